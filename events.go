@@ -55,6 +55,16 @@ const (
 	MesgFuncDeprecated     = "function is deprecated"
 	MesgFuncMissUsed       = "function call is not appropriate in this use-case"
 	MesgFiberLogger        = "handled request"
+	MesgFiberRecover       = "recovered from panic"
+	MesgHTTPLogger         = "handled request"
+	MesgHTTPClient         = "sent request"
+	MesgHTTPDumpRequest    = "request dump"
+	MesgHTTPDumpResponse   = "response dump"
+	MesgGRPCServer         = "handled call"
+	MesgGRPCClient         = "sent call"
 	MesgGormTrace          = "trace"
 	MesgGormUnknown        = "gorm log format not recognized"
+	MesgGormStats          = "query statistics"
+	MesgSQLConnect         = "connect"
+	MesgSQLPrepare         = "prepare"
 )
@@ -15,8 +15,12 @@ import (
 
 func main() {
 	// Get an io.Writer compliant instance that will write messages at the info
-	// level. This uses the Current configured logger.
-	w := logio.NewCurrentWriter(levels.Info)
+	// level. This uses the Current configured logger. Invalid levels are
+	// reported here rather than when writing.
+	w, err := logio.NewCurrentWriterE(levels.Info)
+	if err != nil {
+		panic(err)
+	}
 
 	// io.WriteString accepts an io.Writer as its first argument. This shows
 	// writing the string "foo" to the logger at the info level.
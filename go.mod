@@ -8,6 +8,9 @@ require (
 	github.com/hashicorp/go-hclog v1.0.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel/trace v1.3.0
 	go.uber.org/zap v1.16.0
+	golang.org/x/sys v0.0.0-20210514084401-e8d321eab015
+	google.golang.org/grpc v1.43.0
 	gorm.io/gorm v1.22.3
 )
@@ -0,0 +1,50 @@
+package journald
+
+import (
+	"github.com/roninzo/log"
+)
+
+// Config defines the config for the journald logger.
+type Config struct {
+	// Socket is the path of the native protocol socket of systemd-journald.
+	//
+	// Optional. Default: "/run/systemd/journal/socket"
+	Socket string
+
+	// Identifier is the SYSLOG_IDENTIFIER journal field of the entries, used
+	// when the logger has no prefix.
+	//
+	// Optional. Default: filepath.Base(os.Args[0])
+	Identifier string
+
+	// Fields are extra journal fields added to every entry, i.e.
+	// log.Map{"SERVICE_VERSION": "1.0.0"}. Keys are sanitized the same way
+	// as log.Map keys.
+	//
+	// Optional. Default: nil
+	Fields log.Map
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Socket:     "/run/systemd/journal/socket",
+	Identifier: "",
+	Fields:     nil,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Socket == "" {
+		cfg.Socket = ConfigDefault.Socket
+	}
+	return cfg
+}
@@ -0,0 +1,100 @@
+package journald
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// conn is an unconnected unix datagram socket sending entries to the journal,
+// so that journald restarts do not require reconnecting.
+type conn struct {
+	mu   sync.Mutex
+	sock *net.UnixConn
+	addr *net.UnixAddr
+}
+
+func dial(socket string) (*conn, error) {
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("journald: %w", err)
+	}
+	sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journald: %w", err)
+	}
+	return &conn{
+		sock: sock,
+		addr: &net.UnixAddr{Name: socket, Net: "unixgram"},
+	}, nil
+}
+
+func (c *conn) close() error {
+	return c.sock.Close()
+}
+
+// send sends an entry to the journal. Entries too large to fit in a single
+// datagram are written to a sealed memfd, or to an unlinked temporary file
+// when memfd is not available, whose file descriptor is sent instead.
+func (c *conn) send(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _, err := c.sock.WriteMsgUnix(data, nil, c.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+	file, err := memfd(data)
+	if err != nil {
+		if file, err = tempfd(data); err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+	_, _, err = c.sock.WriteMsgUnix([]byte{}, syscall.UnixRights(int(file.Fd())), c.addr)
+	return err
+}
+
+// memfd returns a sealed memory file holding the data.
+func memfd(data []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("journald", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return nil, err
+	}
+	file := os.NewFile(uintptr(fd), "journald")
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return nil, err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err = unix.FcntlInt(file.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// tempfd returns an unlinked temporary file holding the data.
+func tempfd(data []byte) (*os.File, error) {
+	file, err := ioutil.TempFile("/dev/shm", "journald-")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Remove(file.Name()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package journald
+
+import (
+	"errors"
+)
+
+// conn is not available outside of Linux.
+type conn struct{}
+
+func dial(socket string) (*conn, error) {
+	return nil, errors.New("journald: native protocol is only available on linux")
+}
+
+func (c *conn) close() error {
+	return nil
+}
+
+func (c *conn) send(data []byte) error {
+	return errors.New("journald: native protocol is only available on linux")
+}
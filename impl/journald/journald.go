@@ -0,0 +1,252 @@
+// This package provides a systemd-journald implementation of the log.Logger
+// interface. Entries are sent to the journal using its native protocol, so
+// that log.Map fields land in the journal as real journal fields, i.e.
+// queryable with `journalctl USERID=42`. Levels are mapped to the PRIORITY
+// field and the logger prefix is used as SYSLOG_IDENTIFIER.
+//
+// The native protocol is only available on Linux. On other platforms, New
+// returns an error.
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Logger is a journald based logger that conforms to the log.Logger interface.
+type Logger struct {
+	conn   *conn
+	config Config
+	level  levels.Type
+	prefix string
+}
+
+// New creates a journald logger connected to the journal native socket.
+func New(config ...Config) (*Logger, error) {
+	cfg := configDefault(config...)
+	if cfg.Identifier == "" {
+		cfg.Identifier = filepath.Base(os.Args[0])
+	}
+	c, err := dial(cfg.Socket)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{
+		conn:   c,
+		config: cfg,
+		level:  levels.Info,
+	}, nil
+}
+
+// NewStandard creates a journald logger with the default configuration.
+func NewStandard() (*Logger, error) {
+	return New()
+}
+
+// Close closes the connection to the journal. Note, the connection is shared
+// with all named loggers.
+func (l *Logger) Close() error {
+	return l.conn.close()
+}
+
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{
+		conn:   l.conn,
+		config: l.config,
+		level:  l.level,
+		prefix: log.Prefixed(l.prefix, name),
+	}
+}
+
+func (l *Logger) Options(funcs ...func(*Logger) *Logger) *Logger {
+	for _, f := range funcs {
+		f(l)
+	}
+	return l
+}
+
+func (l *Logger) WithLevel(level levels.Type) *Logger {
+	l.Level(level)
+	return l
+}
+
+func (l *Logger) WithLevelFromDebug(debug bool) *Logger {
+	switch debug {
+	case true:
+		l.Level(levels.Debug)
+	default:
+		l.Level(levels.Info)
+	}
+	return l
+}
+
+func (l *Logger) Prefix(prefix ...string) string {
+	if len(prefix) > 0 {
+		l.prefix = prefix[0]
+	}
+	return l.prefix
+}
+
+func (l *Logger) Level(level ...levels.Type) levels.Type {
+	if len(level) > 0 {
+		l.level = level[0]
+	}
+	return l.level
+}
+
+func (l Logger) Trace(msg ...interface{}) { l.log(levels.Trace, msg...) }
+func (l Logger) Debug(msg ...interface{}) { l.log(levels.Debug, msg...) }
+func (l Logger) Info(msg ...interface{})  { l.log(levels.Info, msg...) }
+func (l Logger) Warn(msg ...interface{})  { l.log(levels.Warn, msg...) }
+func (l Logger) Error(msg ...interface{}) { l.log(levels.Error, msg...) }
+func (l Logger) Panic(msg ...interface{}) { l.log(levels.Panic, msg...) }
+func (l Logger) Fatal(msg ...interface{}) { l.log(levels.Fatal, msg...) }
+
+func (l Logger) Tracef(template string, args ...interface{}) { l.logf(levels.Trace, template, args...) }
+func (l Logger) Debugf(template string, args ...interface{}) { l.logf(levels.Debug, template, args...) }
+func (l Logger) Infof(template string, args ...interface{})  { l.logf(levels.Info, template, args...) }
+func (l Logger) Warnf(template string, args ...interface{})  { l.logf(levels.Warn, template, args...) }
+func (l Logger) Errorf(template string, args ...interface{}) { l.logf(levels.Error, template, args...) }
+func (l Logger) Panicf(template string, args ...interface{}) { l.logf(levels.Panic, template, args...) }
+func (l Logger) Fatalf(template string, args ...interface{}) { l.logf(levels.Fatal, template, args...) }
+
+func (l Logger) log(level levels.Type, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprint(args...), fields)
+}
+
+func (l Logger) logf(level levels.Type, template string, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprintf(template, args...), fields)
+}
+
+func (l Logger) output(level levels.Type, msg string, fields log.Map) {
+	if err := l.conn.send(l.entry(level, msg, fields)); err != nil {
+		fmt.Fprintf(os.Stderr, "journald: could not send entry: %v\n", err)
+	}
+	switch level {
+	case levels.Panic:
+		panic(msg)
+	case levels.Fatal:
+		os.Exit(1)
+	}
+}
+
+// entry serializes a journal entry using the native protocol.
+func (l Logger) entry(level levels.Type, msg string, fields log.Map) []byte {
+	identifier := l.prefix
+	if identifier == "" {
+		identifier = l.config.Identifier
+	}
+	buf := &bytes.Buffer{}
+	field(buf, "MESSAGE", msg)
+	field(buf, "PRIORITY", strconv.Itoa(priority(level)))
+	field(buf, "SYSLOG_IDENTIFIER", identifier)
+	for _, m := range []log.Map{l.config.Fields, fields} {
+		for _, key := range sorted(m) {
+			if strings.HasPrefix(key, "_") { // trusted fields, set by journald only
+				continue
+			}
+			name := Key(key)
+			if reserved[name] {
+				name = "USER_" + name
+			}
+			field(buf, name, fmt.Sprint(m[key]))
+		}
+	}
+	return buf.Bytes()
+}
+
+// reserved are the journal fields written by the logger itself, which the
+// fields of the entries are prefixed with USER_ not to duplicate.
+var reserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+}
+
+// field writes a single field. Values containing newlines are written in the
+// binary form: the name, a newline, the little endian 64bit length of the
+// value, and the value itself.
+func field(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	if strings.ContainsRune(value, '\n') {
+		buf.WriteByte('\n')
+		_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	} else {
+		buf.WriteByte('=')
+	}
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// Key returns a valid journal field name for a log.Map key, i.e. "user-id"
+// becomes "USER_ID". Field names are made of uppercase letters, digits and
+// underscores, may not begin with an underscore (reserved for trusted fields)
+// nor a digit, and are at most 64 characters long.
+func Key(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	key = strings.TrimLeft(key, "_")
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		key = "FIELD_" + key
+	}
+	if len(key) > 64 {
+		key = key[:64]
+	}
+	return key
+}
+
+// priority maps a levels.Type to a syslog priority, as used by the PRIORITY
+// journal field.
+func priority(level levels.Type) int {
+	switch level {
+	case levels.Fatal:
+		return 1 // alert
+	case levels.Panic:
+		return 2 // crit
+	case levels.Error:
+		return 3 // err
+	case levels.Warn:
+		return 4 // warning
+	case levels.Info:
+		return 6 // info
+	default: // levels.Debug, levels.Trace
+		return 7 // debug
+	}
+}
+
+func sorted(fields log.Map) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
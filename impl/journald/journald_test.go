@@ -0,0 +1,157 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+// listen starts an in-process journal, and returns the channel to which
+// received entries are sent.
+func listen(t *testing.T) (string, <-chan map[string]string) {
+	path := filepath.Join(t.TempDir(), "socket")
+	sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.NoError(t, err)
+	t.Cleanup(func() { sock.Close() })
+	entries := make(chan map[string]string, 16)
+	go func() {
+		buf := make([]byte, 64*1024)
+		oob := make([]byte, 1024)
+		for {
+			n, oobn, _, _, err := sock.ReadMsgUnix(buf, oob)
+			if err != nil {
+				return
+			}
+			data := buf[:n]
+			if oobn > 0 { // large entry, passed as a file descriptor
+				msgs, _ := syscall.ParseSocketControlMessage(oob[:oobn])
+				fds, _ := syscall.ParseUnixRights(&msgs[0])
+				file := os.NewFile(uintptr(fds[0]), "entry")
+				data, _ = ioutil.ReadAll(io.NewSectionReader(file, 0, 1<<30))
+				file.Close()
+			}
+			entries <- parse(data)
+		}
+	}()
+	return path, entries
+}
+
+// parse decodes an entry serialized with the native protocol.
+func parse(data []byte) map[string]string {
+	entry := map[string]string{}
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return entry
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if i := strings.IndexByte(line, '='); i >= 0 {
+			entry[line[:i]] = line[i+1:]
+			continue
+		}
+		var n uint64
+		_ = binary.Read(r, binary.LittleEndian, &n)
+		value := make([]byte, n+1) // trailing newline
+		_, _ = io.ReadFull(r, value)
+		entry[line] = string(value[:n])
+	}
+}
+
+func receive(t *testing.T, entries <-chan map[string]string) map[string]string {
+	select {
+	case entry := <-entries:
+		return entry
+	case <-time.After(time.Second):
+		t.Error("no journal entry received")
+		return nil
+	}
+}
+
+func TestLogger(t *testing.T) {
+
+	// Test the logger meets the interface
+	var _ log.Logger = new(Logger)
+
+	socket, entries := listen(t)
+	lgr, err := New(Config{Socket: socket, Identifier: "myapp", Fields: log.Map{"version": "1.0.0"}})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	// Make sure levels are working
+	lgr.Debug("test debug")
+	lgr.Info("test info")
+	assert.Equal(t, map[string]string{
+		"MESSAGE":           "test info",
+		"PRIORITY":          "6",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"VERSION":           "1.0.0",
+	}, receive(t, entries))
+
+	// Test all levels
+	lgr.Level(levels.Trace)
+
+	lgr.Tracef("Hello %s", "World")
+	entry := receive(t, entries)
+	assert.Equal(t, "Hello World", entry["MESSAGE"])
+	assert.Equal(t, "7", entry["PRIORITY"])
+
+	lgr.Warn("foo bar", log.Map{"user-id": 42, "_trusted": "no", "1st": true, "multi": "line\nvalue"})
+	entry = receive(t, entries)
+	assert.Equal(t, "4", entry["PRIORITY"])
+	assert.Equal(t, "42", entry["USER_ID"])
+	assert.NotContains(t, entry, "TRUSTED", "trusted fields are dropped")
+	assert.Equal(t, "true", entry["FIELD_1ST"])
+	assert.Equal(t, "line\nvalue", entry["MULTI"])
+
+	// Test the reserved fields are not duplicated
+	lgr.Info("test reserved", log.Map{"message": "m", "Priority": 0, "syslog-identifier": "other"})
+	entry = receive(t, entries)
+	assert.Equal(t, "test reserved", entry["MESSAGE"])
+	assert.Equal(t, "6", entry["PRIORITY"])
+	assert.Equal(t, "myapp", entry["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "m", entry["USER_MESSAGE"])
+	assert.Equal(t, "0", entry["USER_PRIORITY"])
+	assert.Equal(t, "other", entry["USER_SYSLOG_IDENTIFIER"])
+
+	assert.PanicsWithValue(t, "test panic", func() { lgr.Panic("test panic") })
+	assert.Equal(t, "2", receive(t, entries)["PRIORITY"])
+
+	// Test the prefix is used as SYSLOG_IDENTIFIER
+	lgr.Named("sub").Error("test error")
+	entry = receive(t, entries)
+	assert.Equal(t, "3", entry["PRIORITY"])
+	assert.Equal(t, "sub", entry["SYSLOG_IDENTIFIER"])
+}
+
+func TestLargeEntry(t *testing.T) {
+	socket, entries := listen(t)
+	lgr, err := New(Config{Socket: socket})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	msg := strings.Repeat("x", 4*1024*1024)
+	lgr.Info(msg)
+	assert.Equal(t, msg, receive(t, entries)["MESSAGE"])
+}
+
+func TestMissingSocket(t *testing.T) {
+	_, err := New(Config{Socket: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+}
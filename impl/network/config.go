@@ -0,0 +1,181 @@
+package network
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Encoding is the serialization format of the entries.
+type Encoding int
+
+const (
+	JSONLines Encoding = iota // One JSON object per entry, newline delimited.
+	GELF                      // Graylog Extended Log Format 1.1.
+)
+
+// Backpressure is the behaviour of the logger when its buffer is full.
+type Backpressure int
+
+const (
+	Block Backpressure = iota // Block logging until there is room in the buffer, or until BlockTimeout.
+	Drop                      // Drop the entry, without blocking.
+)
+
+// Config defines the config for the network logger.
+type Config struct {
+	// Network is the transport used to ship entries: "tcp", "udp" or "http".
+	//
+	// Optional. Default: "tcp"
+	Network string
+
+	// Address is the address of the collector, i.e. "localhost:12201" for
+	// tcp and udp, or the URL entries are posted to for http, i.e.
+	// "https://localhost:8080/logs".
+	//
+	// Required.
+	Address string
+
+	// TLSConfig enables TLS on tcp connections when not nil. For http, TLS
+	// is enabled by the https URL scheme and TLSConfig is used by the client
+	// transport.
+	//
+	// Optional. Default: nil
+	TLSConfig *tls.Config
+
+	// Encoding defines how entries are serialized.
+	//
+	// Optional. Default: JSONLines
+	Encoding Encoding
+
+	// Host is the name of the host sending the entries, as used by GELF.
+	//
+	// Optional. Default: os.Hostname()
+	Host string
+
+	// BufferSize is the maximum number of entries waiting to be shipped.
+	//
+	// Optional. Default: 1024
+	BufferSize int
+
+	// Backpressure defines what happens when the buffer is full.
+	//
+	// Optional. Default: Block
+	Backpressure Backpressure
+
+	// BlockTimeout is the maximum time logging blocks when the buffer is
+	// full, before the entry is dropped. A zero value blocks indefinitely.
+	//
+	// Optional. Default: 0
+	BlockTimeout time.Duration
+
+	// BatchSize is the maximum number of entries shipped at once, i.e. in a
+	// single http request.
+	//
+	// Optional. Default: 100
+	BatchSize int
+
+	// FlushInterval is the maximum time an entry waits in the buffer for a
+	// batch to fill up.
+	//
+	// Optional. Default: 1 * time.Second
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times shipping a batch is retried before
+	// its entries are counted as failed. A negative value disables retries.
+	//
+	// Optional. Default: 3
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry, doubled on each
+	// subsequent retry.
+	//
+	// Optional. Default: 100 * time.Millisecond
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries.
+	//
+	// Optional. Default: 5 * time.Second
+	MaxBackoff time.Duration
+
+	// Timeout is the maximum time a dial, a write or an http request may
+	// take.
+	//
+	// Optional. Default: 5 * time.Second
+	Timeout time.Duration
+
+	// ChunkSize is the maximum size of an udp datagram. Larger GELF messages
+	// are chunked, up to 128 chunks.
+	//
+	// Optional. Default: 1420
+	ChunkSize int
+
+	// Header are extra http request headers, i.e. for authentication.
+	//
+	// Optional. Default: nil
+	Header http.Header
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Network:       "tcp",
+	Address:       "",
+	TLSConfig:     nil,
+	Encoding:      JSONLines,
+	Host:          "",
+	BufferSize:    1024,
+	Backpressure:  Block,
+	BlockTimeout:  0,
+	BatchSize:     100,
+	FlushInterval: 1 * time.Second,
+	MaxRetries:    3,
+	MinBackoff:    100 * time.Millisecond,
+	MaxBackoff:    5 * time.Second,
+	Timeout:       5 * time.Second,
+	ChunkSize:     1420,
+	Header:        nil,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Network == "" {
+		cfg.Network = ConfigDefault.Network
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = ConfigDefault.BufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = ConfigDefault.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = ConfigDefault.FlushInterval
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = ConfigDefault.MaxRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = ConfigDefault.MinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = ConfigDefault.MaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+	if cfg.ChunkSize <= 12 { // room for the GELF chunk header
+		cfg.ChunkSize = ConfigDefault.ChunkSize
+	}
+	return cfg
+}
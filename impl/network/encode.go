@@ -0,0 +1,134 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// entry is a log record waiting to be shipped.
+type entry struct {
+	time   time.Time
+	level  levels.Type
+	prefix string
+	msg    string
+	fields log.Map
+}
+
+// encode serializes an entry, without framing. The field values which cannot
+// be marshalled, i.e. channels, functions or NaN, are rendered with fmt.Sprint
+// so that the entry is still shipped.
+func (s *shipper) encode(e entry) ([]byte, error) {
+	b, err := s.marshal(e)
+	if err != nil {
+		e.fields = printable(e.fields)
+		b, err = s.marshal(e)
+	}
+	return b, err
+}
+
+// marshal serializes an entry as per the encoding.
+func (s *shipper) marshal(e entry) ([]byte, error) {
+	switch s.Encoding {
+	case GELF:
+		return json.Marshal(gelf(s.Host, e))
+	default:
+		return json.Marshal(jsonLine(e))
+	}
+}
+
+// jsonLine returns the JSON object of an entry. Fields are at the top level,
+// but cannot override the time, level, prefix and msg keys.
+func jsonLine(e entry) map[string]interface{} {
+	obj := make(map[string]interface{}, len(e.fields)+4)
+	for key, val := range e.fields {
+		obj[key] = value(val)
+	}
+	obj["time"] = e.time.Format(time.RFC3339Nano)
+	obj["level"] = e.level.String()
+	obj["msg"] = e.msg
+	if e.prefix != "" {
+		obj["prefix"] = e.prefix
+	}
+	return obj
+}
+
+// gelfFieldName is the pattern additional GELF field names must match.
+var gelfFieldName = regexp.MustCompile(`[^\w\.\-]`)
+
+// gelf returns the GELF 1.1 payload of an entry. Fields are additional fields
+// prefixed with an underscore, and their values are either strings or
+// numbers.
+func gelf(host string, e entry) map[string]interface{} {
+	obj := make(map[string]interface{}, len(e.fields)+6)
+	for key, val := range e.fields {
+		key = "_" + gelfFieldName.ReplaceAllString(key, "_")
+		if key == "_id" { // reserved
+			key = "__id"
+		}
+		switch v := value(val).(type) {
+		case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			obj[key] = v
+		default:
+			obj[key] = fmt.Sprint(v)
+		}
+	}
+	obj["version"] = "1.1"
+	obj["host"] = host
+	obj["short_message"] = e.msg
+	obj["timestamp"] = float64(e.time.UnixNano()/int64(time.Millisecond)) / 1e3
+	obj["level"] = severity(e.level)
+	if e.prefix != "" {
+		obj["_prefix"] = e.prefix
+	}
+	return obj
+}
+
+// value returns a JSON friendly value, i.e. errors are rendered as strings.
+func value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case []byte:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return v
+	}
+}
+
+// printable returns the fields with the values which cannot be marshalled
+// rendered with fmt.Sprint.
+func printable(fields log.Map) log.Map {
+	ret := make(log.Map, len(fields))
+	for key, val := range fields {
+		if _, err := json.Marshal(value(val)); err != nil {
+			val = fmt.Sprint(val)
+		}
+		ret[key] = val
+	}
+	return ret
+}
+
+// severity maps a levels.Type to a syslog severity, as used by GELF.
+func severity(level levels.Type) int {
+	switch level {
+	case levels.Fatal:
+		return 1 // alert
+	case levels.Panic:
+		return 2 // critical
+	case levels.Error:
+		return 3 // error
+	case levels.Warn:
+		return 4 // warning
+	case levels.Info:
+		return 6 // informational
+	default: // levels.Debug, levels.Trace
+		return 7 // debug
+	}
+}
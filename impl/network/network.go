@@ -0,0 +1,160 @@
+// This package provides a network implementation of the log.Logger interface,
+// shipping entries straight to a log collector. Entries are serialized as JSON
+// lines or GELF, and shipped over tcp (optionally with TLS), udp (with GELF
+// chunking) or http POST batches.
+//
+// Entries are buffered in memory and shipped asynchronously by a single
+// goroutine, retrying with exponential backoff on failure. When the buffer is
+// full, logging either blocks or drops entries, depending on the configured
+// Backpressure. Delivery metrics are available through Stats. Entries are
+// shipped at least once: a batch failing half way through a tcp write is
+// shipped again in full.
+
+package network
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Logger is a network based logger that conforms to the log.Logger interface.
+type Logger struct {
+	shipper *shipper
+	level   levels.Type
+	prefix  string
+}
+
+// New creates a network logger shipping entries to the configured address.
+// Connections are established lazily, when the first batch is shipped.
+func New(config ...Config) (*Logger, error) {
+	cfg := configDefault(config...)
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	s, err := newShipper(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{
+		shipper: s,
+		level:   levels.Info,
+	}, nil
+}
+
+// Flush blocks until all the entries logged so far were shipped, or failed.
+func (l *Logger) Flush() {
+	l.shipper.flush()
+}
+
+// Close ships the buffered entries and closes the connection to the
+// collector. Entries logged afterwards are dropped. Note, the shipper is
+// shared with all named loggers.
+func (l *Logger) Close() error {
+	return l.shipper.close()
+}
+
+// Stats returns the delivery metrics of the logger, shared with all named
+// loggers.
+func (l *Logger) Stats() Stats {
+	return l.shipper.stats()
+}
+
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{
+		shipper: l.shipper,
+		level:   l.level,
+		prefix:  log.Prefixed(l.prefix, name),
+	}
+}
+
+func (l *Logger) Options(funcs ...func(*Logger) *Logger) *Logger {
+	for _, f := range funcs {
+		f(l)
+	}
+	return l
+}
+
+func (l *Logger) WithLevel(level levels.Type) *Logger {
+	l.Level(level)
+	return l
+}
+
+func (l *Logger) WithLevelFromDebug(debug bool) *Logger {
+	switch debug {
+	case true:
+		l.Level(levels.Debug)
+	default:
+		l.Level(levels.Info)
+	}
+	return l
+}
+
+func (l *Logger) Prefix(prefix ...string) string {
+	if len(prefix) > 0 {
+		l.prefix = prefix[0]
+	}
+	return l.prefix
+}
+
+func (l *Logger) Level(level ...levels.Type) levels.Type {
+	if len(level) > 0 {
+		l.level = level[0]
+	}
+	return l.level
+}
+
+func (l Logger) Trace(msg ...interface{}) { l.log(levels.Trace, msg...) }
+func (l Logger) Debug(msg ...interface{}) { l.log(levels.Debug, msg...) }
+func (l Logger) Info(msg ...interface{})  { l.log(levels.Info, msg...) }
+func (l Logger) Warn(msg ...interface{})  { l.log(levels.Warn, msg...) }
+func (l Logger) Error(msg ...interface{}) { l.log(levels.Error, msg...) }
+func (l Logger) Panic(msg ...interface{}) { l.log(levels.Panic, msg...) }
+func (l Logger) Fatal(msg ...interface{}) { l.log(levels.Fatal, msg...) }
+
+func (l Logger) Tracef(template string, args ...interface{}) { l.logf(levels.Trace, template, args...) }
+func (l Logger) Debugf(template string, args ...interface{}) { l.logf(levels.Debug, template, args...) }
+func (l Logger) Infof(template string, args ...interface{})  { l.logf(levels.Info, template, args...) }
+func (l Logger) Warnf(template string, args ...interface{})  { l.logf(levels.Warn, template, args...) }
+func (l Logger) Errorf(template string, args ...interface{}) { l.logf(levels.Error, template, args...) }
+func (l Logger) Panicf(template string, args ...interface{}) { l.logf(levels.Panic, template, args...) }
+func (l Logger) Fatalf(template string, args ...interface{}) { l.logf(levels.Fatal, template, args...) }
+
+func (l Logger) log(level levels.Type, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprint(args...), fields)
+}
+
+func (l Logger) logf(level levels.Type, template string, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprintf(template, args...), fields)
+}
+
+func (l Logger) output(level levels.Type, msg string, fields log.Map) {
+	l.shipper.enqueue(entry{
+		time:   time.Now(),
+		level:  level,
+		prefix: l.prefix,
+		msg:    msg,
+		fields: fields,
+	})
+	switch level {
+	case levels.Panic:
+		l.shipper.flush()
+		panic(msg)
+	case levels.Fatal:
+		_ = l.shipper.close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,269 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+// listen starts an in-process collector, and returns the channel to which
+// received messages are sent, split on delim.
+func listen(t *testing.T, ln net.Listener, delim byte) <-chan string {
+	t.Cleanup(func() { ln.Close() })
+	msgs := make(chan string, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					msg, err := r.ReadString(delim)
+					if err != nil {
+						return
+					}
+					msgs <- strings.TrimSuffix(msg, string(delim))
+				}
+			}()
+		}
+	}()
+	return msgs
+}
+
+func receive(t *testing.T, msgs <-chan string) map[string]interface{} {
+	select {
+	case msg := <-msgs:
+		obj := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(msg), &obj), msg)
+		return obj
+	case <-time.After(2 * time.Second):
+		t.Error("no message received")
+		return nil
+	}
+}
+
+func TestLogger(t *testing.T) {
+
+	// Test the logger meets the interface
+	var _ log.Logger = new(Logger)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	msgs := listen(t, ln, '\n')
+
+	lgr, err := New(Config{Network: "tcp", Address: ln.Addr().String()})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	// Make sure levels are working
+	lgr.Debug("test debug")
+	lgr.Info("test info")
+	lgr.Flush()
+	obj := receive(t, msgs)
+	assert.Equal(t, "info", obj["level"])
+	assert.Equal(t, "test info", obj["msg"])
+	assert.NotEmpty(t, obj["time"])
+
+	// Test all levels
+	lgr.Level(levels.Trace)
+
+	lgr.Tracef("Hello %s", "World")
+	lgr.Named("sub").Warn("foo bar", log.Map{"baz": "qux", "msg": "ignored", "n": 42})
+	lgr.Flush()
+	obj = receive(t, msgs)
+	assert.Equal(t, "trace", obj["level"])
+	assert.Equal(t, "Hello World", obj["msg"])
+	obj = receive(t, msgs)
+	assert.Equal(t, "warning", obj["level"])
+	assert.Equal(t, "foo bar", obj["msg"])
+	assert.Equal(t, "sub", obj["prefix"])
+	assert.Equal(t, "qux", obj["baz"])
+	assert.Equal(t, float64(42), obj["n"])
+
+	// Test the values which cannot be marshalled are printed
+	lgr.Info("unmarshallable", log.Map{"nan": math.NaN(), "ch": make(chan int), "ok": "yes"})
+	lgr.Flush()
+	obj = receive(t, msgs)
+	assert.Equal(t, "unmarshallable", obj["msg"])
+	assert.Equal(t, "NaN", obj["nan"])
+	assert.Regexp(t, `^0x[0-9a-f]+$`, obj["ch"])
+	assert.Equal(t, "yes", obj["ok"])
+
+	assert.PanicsWithValue(t, "test panic", func() { lgr.Panic("test panic") })
+	assert.Equal(t, "panic", receive(t, msgs)["level"])
+
+	assert.Equal(t, Stats{Queued: 5, Sent: 5}, lgr.Stats())
+}
+
+func TestGELFOverTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+	assert.NoError(t, err)
+	msgs := listen(t, ln, 0)
+
+	lgr, err := New(Config{
+		Network:   "tcp",
+		Address:   ln.Addr().String(),
+		TLSConfig: srv.Client().Transport.(*http.Transport).TLSClientConfig,
+		Encoding:  GELF,
+		Host:      "myhost",
+	})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Prefix("myapp")
+	lgr.Error("foo bar", log.Map{"user id": 42, "id": "x", "ok": true})
+	lgr.Flush()
+	obj := receive(t, msgs)
+	assert.Equal(t, "1.1", obj["version"])
+	assert.Equal(t, "myhost", obj["host"])
+	assert.Equal(t, "foo bar", obj["short_message"])
+	assert.Equal(t, float64(3), obj["level"])
+	assert.Equal(t, "myapp", obj["_prefix"])
+	assert.Equal(t, float64(42), obj["_user_id"])
+	assert.Equal(t, "x", obj["__id"])
+	assert.Equal(t, "true", obj["_ok"])
+	assert.NotZero(t, obj["timestamp"])
+}
+
+func TestGELFChunking(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	lgr, err := New(Config{Network: "udp", Address: conn.LocalAddr().String(), Encoding: GELF, ChunkSize: 100})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	msg := strings.Repeat("x", 500)
+	lgr.Info(msg)
+	lgr.Flush()
+
+	// Reassemble chunks
+	var (
+		count  = -1
+		chunks = map[byte][]byte{}
+		buf    = make([]byte, 1024)
+	)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for count < 0 || len(chunks) < count {
+		n, _, err := conn.ReadFrom(buf)
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		assert.LessOrEqual(t, n, 100)
+		assert.Equal(t, []byte{0x1e, 0x0f}, buf[:2])
+		chunks[buf[10]] = append([]byte{}, buf[12:n]...)
+		count = int(buf[11])
+	}
+	full := &bytes.Buffer{}
+	for seq := 0; seq < count; seq++ {
+		full.Write(chunks[byte(seq)])
+	}
+	obj := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(full.Bytes(), &obj))
+	assert.Equal(t, msg, obj["short_message"])
+}
+
+func TestHTTPBatchRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		bodies   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	lgr, err := New(Config{
+		Network:    "http",
+		Address:    srv.URL,
+		BatchSize:  2,
+		MinBackoff: time.Millisecond,
+		Header:     http.Header{"Authorization": []string{"secret"}},
+	})
+	assert.NoError(t, err)
+
+	lgr.Info("foo")
+	lgr.Info("bar")
+	lgr.Info("baz")
+	assert.NoError(t, lgr.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, requests) // failed batch, retried batch, last partial batch
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, 2, strings.Count(bodies[0], "\n"))
+	assert.Contains(t, bodies[0], `"msg":"foo"`)
+	assert.Contains(t, bodies[0], `"msg":"bar"`)
+	assert.Contains(t, bodies[1], `"msg":"baz"`)
+	assert.Equal(t, Stats{Queued: 3, Sent: 3, Retries: 1}, lgr.Stats())
+
+	// Entries logged after closing are dropped
+	lgr.Info("qux")
+	assert.Equal(t, uint64(1), lgr.Stats().Dropped)
+}
+
+func TestHTTPPermanentFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	lgr, err := New(Config{Network: "http", Address: srv.URL})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Info("foo")
+	lgr.Flush()
+	assert.Equal(t, Stats{Queued: 1, Failed: 1}, lgr.Stats())
+}
+
+func TestBackpressureDrop(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	lgr, err := New(Config{Network: "http", Address: srv.URL, BufferSize: 1, BatchSize: 1, Backpressure: Drop})
+	assert.NoError(t, err)
+
+	lgr.Info("foo") // being shipped, blocked by the collector
+	assert.Eventually(t, func() bool { return lgr.Stats().Pending == 0 }, time.Second, time.Millisecond)
+	lgr.Info("bar") // buffered
+	lgr.Info("baz") // dropped
+	close(release)
+	assert.NoError(t, lgr.Close())
+	assert.Equal(t, Stats{Queued: 2, Sent: 2, Dropped: 1}, lgr.Stats())
+}
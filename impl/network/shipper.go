@@ -0,0 +1,204 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the delivery metrics of a network logger.
+type Stats struct {
+	Queued  uint64 // Entries accepted in the buffer.
+	Sent    uint64 // Entries shipped successfully.
+	Dropped uint64 // Entries dropped because the buffer was full, or the logger closed.
+	Failed  uint64 // Entries that could not be encoded or shipped, after retries.
+	Retries uint64 // Shipping attempts that were retried.
+	Pending int    // Entries currently waiting in the buffer.
+}
+
+// item is an element of the shipper queue: either an entry, or a flush
+// request acknowledged once all previous entries were processed.
+type item struct {
+	entry entry
+	flush chan struct{}
+}
+
+// shipper buffers entries and ships them in batches from a single goroutine.
+// It is shared by all the loggers derived from one another.
+type shipper struct {
+	// Counters first, for 64bit alignment of atomic operations.
+	queued  uint64
+	sent    uint64
+	dropped uint64
+	failed  uint64
+	retries uint64
+
+	Config
+
+	transport transport
+	queue     chan item
+	done      chan struct{}
+	mu        sync.RWMutex // guards closed, and sending to queue
+	closed    bool
+}
+
+func newShipper(cfg Config) (*shipper, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("network: missing address")
+	}
+	t, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &shipper{
+		Config:    cfg,
+		transport: t,
+		queue:     make(chan item, cfg.BufferSize),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// enqueue adds an entry to the buffer, applying backpressure when full.
+func (s *shipper) enqueue(e entry) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		atomic.AddUint64(&s.dropped, 1)
+		return
+	}
+	select {
+	case s.queue <- item{entry: e}:
+		atomic.AddUint64(&s.queued, 1)
+		return
+	default:
+	}
+	if s.Backpressure == Drop {
+		atomic.AddUint64(&s.dropped, 1)
+		return
+	}
+	var timeout <-chan time.Time
+	if s.BlockTimeout > 0 {
+		timer := time.NewTimer(s.BlockTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case s.queue <- item{entry: e}:
+		atomic.AddUint64(&s.queued, 1)
+	case <-timeout:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// flush blocks until all the entries buffered so far were processed.
+func (s *shipper) flush() {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return
+	}
+	ack := make(chan struct{})
+	s.queue <- item{flush: ack}
+	s.mu.RUnlock()
+	<-ack
+}
+
+// close ships the buffered entries, and stops the shipper.
+func (s *shipper) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	<-s.done
+	return s.transport.close()
+}
+
+func (s *shipper) stats() Stats {
+	return Stats{
+		Queued:  atomic.LoadUint64(&s.queued),
+		Sent:    atomic.LoadUint64(&s.sent),
+		Dropped: atomic.LoadUint64(&s.dropped),
+		Failed:  atomic.LoadUint64(&s.failed),
+		Retries: atomic.LoadUint64(&s.retries),
+		Pending: len(s.queue),
+	}
+}
+
+// run accumulates entries into batches, shipped when BatchSize is reached,
+// when FlushInterval elapsed, on flush requests and when closing.
+func (s *shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.BatchSize)
+	ship := func() {
+		if len(batch) > 0 {
+			s.ship(batch)
+			batch = make([][]byte, 0, s.BatchSize)
+		}
+	}
+	for {
+		select {
+		case it, ok := <-s.queue:
+			switch {
+			case !ok:
+				ship()
+				return
+			case it.flush != nil:
+				ship()
+				close(it.flush)
+			default:
+				msg, err := s.encode(it.entry)
+				if err != nil {
+					atomic.AddUint64(&s.failed, 1)
+					fmt.Fprintf(os.Stderr, "network: could not encode entry: %v\n", err)
+					continue
+				}
+				if batch = append(batch, msg); len(batch) >= s.BatchSize {
+					ship()
+				}
+			}
+		case <-ticker.C:
+			ship()
+		}
+	}
+}
+
+// ship sends a batch, retrying with exponential backoff on failure.
+func (s *shipper) ship(batch [][]byte) {
+	backoff := s.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.transport.send(batch)
+		if err == nil {
+			atomic.AddUint64(&s.sent, uint64(len(batch)))
+			return
+		}
+		var partial partialError
+		if errors.As(err, &partial) {
+			atomic.AddUint64(&s.sent, uint64(partial.sent))
+			batch = batch[partial.sent:]
+		}
+		if attempt >= s.MaxRetries || isPermanent(err) {
+			atomic.AddUint64(&s.failed, uint64(len(batch)))
+			fmt.Fprintf(os.Stderr, "network: could not ship %d entries: %v\n", len(batch), err)
+			return
+		}
+		atomic.AddUint64(&s.retries, 1)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
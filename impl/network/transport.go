@@ -0,0 +1,249 @@
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// transport ships batches of encoded entries to the collector.
+type transport interface {
+	send(msgs [][]byte) error
+	close() error
+}
+
+// permanentError is an error that is not worth retrying.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func newTransport(cfg Config) (transport, error) {
+	switch cfg.Network {
+	case "tcp", "tcp4", "tcp6":
+		return &streamTransport{cfg: cfg}, nil
+	case "udp", "udp4", "udp6":
+		return &packetTransport{cfg: cfg}, nil
+	case "http", "https":
+		client := &http.Client{Timeout: cfg.Timeout}
+		if cfg.TLSConfig != nil {
+			client.Transport = &http.Transport{TLSClientConfig: cfg.TLSConfig}
+		}
+		return &httpTransport{cfg: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("network: unknown network %q", cfg.Network)
+	}
+}
+
+// streamTransport ships entries over a, possibly TLS, tcp connection. JSON
+// lines are newline delimited, GELF messages are null byte delimited.
+type streamTransport struct {
+	cfg  Config
+	conn net.Conn
+}
+
+func (t *streamTransport) send(msgs [][]byte) error {
+	if t.conn == nil {
+		dialer := &net.Dialer{Timeout: t.cfg.Timeout}
+		var (
+			conn net.Conn
+			err  error
+		)
+		if t.cfg.TLSConfig != nil {
+			conn, err = tls.DialWithDialer(dialer, t.cfg.Network, t.cfg.Address, t.cfg.TLSConfig)
+		} else {
+			conn, err = dialer.Dial(t.cfg.Network, t.cfg.Address)
+		}
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+	}
+	delim := byte('\n')
+	if t.cfg.Encoding == GELF {
+		delim = 0
+	}
+	buf := &bytes.Buffer{}
+	for _, msg := range msgs {
+		buf.Write(msg)
+		buf.WriteByte(delim)
+	}
+	_ = t.conn.SetWriteDeadline(time.Now().Add(t.cfg.Timeout))
+	if _, err := t.conn.Write(buf.Bytes()); err != nil {
+		_ = t.close()
+		return err
+	}
+	return nil
+}
+
+func (t *streamTransport) close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// packetTransport ships entries over udp, one datagram per entry. GELF
+// messages larger than ChunkSize are chunked.
+type packetTransport struct {
+	cfg  Config
+	conn net.Conn
+}
+
+const (
+	gelfChunkHeader = 12  // magic bytes, message id, sequence number and count
+	gelfMaxChunks   = 128 // maximum sequence count
+)
+
+func (t *packetTransport) send(msgs [][]byte) error {
+	if t.conn == nil {
+		conn, err := net.DialTimeout(t.cfg.Network, t.cfg.Address, t.cfg.Timeout)
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+	}
+	for i, msg := range msgs {
+		if err := t.write(msg); err != nil {
+			if i > 0 { // do not ship the same entries twice on retry
+				return partialError{sent: i, err: err}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *packetTransport) write(msg []byte) error {
+	datagrams := [][]byte{msg}
+	if t.cfg.Encoding == GELF && len(msg) > t.cfg.ChunkSize {
+		var err error
+		if datagrams, err = chunk(msg, t.cfg.ChunkSize); err != nil {
+			return err
+		}
+	}
+	for _, datagram := range datagrams {
+		if _, err := t.conn.Write(datagram); err != nil {
+			_ = t.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *packetTransport) close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// chunk splits a GELF message into chunks of at most size bytes, each
+// starting with the 0x1e 0x0f magic bytes, an 8 bytes message id shared by all
+// chunks, the chunk sequence number and the sequence count.
+func chunk(msg []byte, size int) ([][]byte, error) {
+	payload := size - gelfChunkHeader
+	count := (len(msg) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return nil, permanentError{fmt.Errorf("network: GELF message too large: %d bytes", len(msg))}
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for seq := 0; seq < count; seq++ {
+		end := (seq + 1) * payload
+		if end > len(msg) {
+			end = len(msg)
+		}
+		c := make([]byte, 0, gelfChunkHeader+end-seq*payload)
+		c = append(c, 0x1e, 0x0f)
+		c = append(c, id...)
+		c = append(c, byte(seq), byte(count))
+		c = append(c, msg[seq*payload:end]...)
+		chunks = append(chunks, c)
+	}
+	return chunks, nil
+}
+
+// httpTransport ships entries with http POST requests. JSON lines are sent in
+// batches, one request per batch, whereas GELF messages are sent one request
+// per entry, as expected by GELF http inputs.
+type httpTransport struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (t *httpTransport) send(msgs [][]byte) error {
+	if t.cfg.Encoding == GELF {
+		for i, msg := range msgs {
+			if err := t.post("application/json", msg); err != nil {
+				if i > 0 { // do not ship the same entries twice on retry
+					return partialError{sent: i, err: err}
+				}
+				return err
+			}
+		}
+		return nil
+	}
+	return t.post("application/x-ndjson", append(bytes.Join(msgs, []byte{'\n'}), '\n'))
+}
+
+func (t *httpTransport) post(contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.cfg.Address, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	for key, vals := range t.cfg.Header {
+		req.Header[key] = vals
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("network: unexpected http status: %s", resp.Status)
+	default:
+		return permanentError{fmt.Errorf("network: unexpected http status: %s", resp.Status)}
+	}
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// partialError is an error that occurred after part of a batch was shipped.
+type partialError struct {
+	sent int
+	err  error
+}
+
+func (e partialError) Error() string { return e.err.Error() }
+func (e partialError) Unwrap() error { return e.err }
+
+// isPermanent reports whether an error is not worth retrying.
+func isPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
@@ -0,0 +1,137 @@
+package syslog
+
+import (
+	"time"
+)
+
+// Format is the syslog message format written on the wire.
+type Format int
+
+const (
+	RFC5424 Format = iota // The Syslog Protocol, with structured data.
+	RFC3164               // The BSD syslog Protocol, with fields appended to the message.
+)
+
+// Facility is the syslog facility code of the messages.
+type Facility int
+
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	Lpr
+	News
+	Uucp
+	Cron
+	Authpriv
+	Ftp
+	_ // ntp
+	_ // log audit
+	_ // log alert
+	_ // clock daemon
+	Local0
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Config defines the config for the syslog logger.
+type Config struct {
+	// Network is the transport used to reach the syslog daemon: "unixgram",
+	// "unix", "udp", "tcp", or any other network supported by net.Dial. When
+	// empty, the local syslog daemon is looked for on its usual unix sockets.
+	//
+	// Optional. Default: ""
+	Network string
+
+	// Address is the address of the syslog daemon, i.e. "localhost:514" or
+	// "/dev/log". When empty with an empty Network, the local syslog daemon
+	// is looked for on its usual unix sockets.
+	//
+	// Optional. Default: ""
+	Address string
+
+	// Format defines the message format.
+	//
+	// Optional. Default: RFC5424
+	Format Format
+
+	// Facility defines the facility code of the messages. Note, Kern is
+	// reserved to the kernel and is replaced by the default.
+	//
+	// Optional. Default: User
+	Facility Facility
+
+	// Hostname is the HOSTNAME header field of the messages.
+	//
+	// Optional. Default: os.Hostname()
+	Hostname string
+
+	// AppName is the APP-NAME header field (or TAG in RFC3164) of the
+	// messages, used when the logger has no prefix.
+	//
+	// Optional. Default: filepath.Base(os.Args[0])
+	AppName string
+
+	// StructuredDataID is the SD-ID under which fields are rendered as RFC5424
+	// structured data. It must contain an "@" followed by a private enterprise
+	// number, unless it is an IANA registered SD-ID.
+	//
+	// Optional. Default: "fields@32473"
+	StructuredDataID string
+
+	// OctetCounting enables the octet counting framing of RFC6587 for stream
+	// based networks, i.e. tcp. Otherwise, messages are newline terminated.
+	//
+	// Optional. Default: false
+	OctetCounting bool
+
+	// Timeout is the maximum amount of time a dial or a write will wait for
+	// the syslog daemon.
+	//
+	// Optional. Default: 5 * time.Second
+	Timeout time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Network:          "",
+	Address:          "",
+	Format:           RFC5424,
+	Facility:         User,
+	Hostname:         "",
+	AppName:          "",
+	StructuredDataID: "fields@32473",
+	OctetCounting:    false,
+	Timeout:          5 * time.Second,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Facility == Kern {
+		cfg.Facility = ConfigDefault.Facility
+	}
+	if cfg.StructuredDataID == "" {
+		cfg.StructuredDataID = ConfigDefault.StructuredDataID
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+	return cfg
+}
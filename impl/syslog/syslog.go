@@ -0,0 +1,154 @@
+// This package provides a syslog implementation of the log.Logger interface.
+// Messages are written to a syslog daemon, either local via a unix socket or
+// remote via udp or tcp, using the RFC5424 or the RFC3164 message format.
+// Levels are mapped to syslog severities, log.Map fields are rendered as
+// RFC5424 structured data, and the logger prefix is used as APP-NAME.
+
+package syslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Logger is a syslog based logger that conforms to the log.Logger interface.
+type Logger struct {
+	writer *writer
+	level  levels.Type
+	prefix string
+}
+
+// New creates a syslog logger and connects it to the syslog daemon found at
+// the configured network address.
+func New(config ...Config) (*Logger, error) {
+	cfg := configDefault(config...)
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	w, err := newWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{
+		writer: w,
+		level:  levels.Info,
+	}, nil
+}
+
+// NewStandard creates a syslog logger connected to the local syslog daemon.
+func NewStandard() (*Logger, error) {
+	return New()
+}
+
+// Close closes the connection to the syslog daemon. Note, the connection is
+// shared with all named loggers.
+func (l *Logger) Close() error {
+	return l.writer.close()
+}
+
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{
+		writer: l.writer,
+		level:  l.level,
+		prefix: log.Prefixed(l.prefix, name),
+	}
+}
+
+func (l *Logger) Options(funcs ...func(*Logger) *Logger) *Logger {
+	for _, f := range funcs {
+		f(l)
+	}
+	return l
+}
+
+func (l *Logger) WithLevel(level levels.Type) *Logger {
+	l.Level(level)
+	return l
+}
+
+func (l *Logger) WithLevelFromDebug(debug bool) *Logger {
+	switch debug {
+	case true:
+		l.Level(levels.Debug)
+	default:
+		l.Level(levels.Info)
+	}
+	return l
+}
+
+func (l *Logger) Prefix(prefix ...string) string {
+	if len(prefix) > 0 {
+		l.prefix = prefix[0]
+	}
+	return l.prefix
+}
+
+func (l *Logger) Level(level ...levels.Type) levels.Type {
+	if len(level) > 0 {
+		l.level = level[0]
+	}
+	return l.level
+}
+
+func (l Logger) Trace(msg ...interface{}) { l.log(levels.Trace, msg...) }
+func (l Logger) Debug(msg ...interface{}) { l.log(levels.Debug, msg...) }
+func (l Logger) Info(msg ...interface{})  { l.log(levels.Info, msg...) }
+func (l Logger) Warn(msg ...interface{})  { l.log(levels.Warn, msg...) }
+func (l Logger) Error(msg ...interface{}) { l.log(levels.Error, msg...) }
+func (l Logger) Panic(msg ...interface{}) { l.log(levels.Panic, msg...) }
+func (l Logger) Fatal(msg ...interface{}) { l.log(levels.Fatal, msg...) }
+
+func (l Logger) Tracef(template string, args ...interface{}) { l.logf(levels.Trace, template, args...) }
+func (l Logger) Debugf(template string, args ...interface{}) { l.logf(levels.Debug, template, args...) }
+func (l Logger) Infof(template string, args ...interface{})  { l.logf(levels.Info, template, args...) }
+func (l Logger) Warnf(template string, args ...interface{})  { l.logf(levels.Warn, template, args...) }
+func (l Logger) Errorf(template string, args ...interface{}) { l.logf(levels.Error, template, args...) }
+func (l Logger) Panicf(template string, args ...interface{}) { l.logf(levels.Panic, template, args...) }
+func (l Logger) Fatalf(template string, args ...interface{}) { l.logf(levels.Fatal, template, args...) }
+
+func (l Logger) log(level levels.Type, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprint(args...), fields)
+}
+
+func (l Logger) logf(level levels.Type, template string, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprintf(template, args...), fields)
+}
+
+func (l Logger) output(level levels.Type, msg string, fields log.Map) {
+	err := l.writer.write(l.writer.format(time.Now(), level, l.appName(), msg, fields))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "syslog: could not write message: %v\n", err)
+	}
+	switch level {
+	case levels.Panic:
+		panic(msg)
+	case levels.Fatal:
+		os.Exit(1)
+	}
+}
+
+// appName returns the logger prefix, or the configured AppName if missing.
+func (l Logger) appName() string {
+	if l.prefix != "" {
+		return l.prefix
+	}
+	return l.writer.AppName
+}
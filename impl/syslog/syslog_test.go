@@ -0,0 +1,156 @@
+package syslog
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+// listen starts an in-process syslog daemon, and returns the channel to which
+// received messages are sent.
+func listen(t *testing.T, network, address string) (string, <-chan string) {
+	msgs := make(chan string, 16)
+	switch network {
+	case "tcp", "unix":
+		ln, err := net.Listen(network, address)
+		assert.NoError(t, err)
+		t.Cleanup(func() { ln.Close() })
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					scanner := bufio.NewScanner(conn)
+					for scanner.Scan() {
+						msgs <- scanner.Text()
+					}
+				}()
+			}
+		}()
+		return ln.Addr().String(), msgs
+	default:
+		conn, err := net.ListenPacket(network, address)
+		assert.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		go func() {
+			buf := make([]byte, 64*1024)
+			for {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				msgs <- string(buf[:n])
+			}
+		}()
+		return conn.LocalAddr().String(), msgs
+	}
+}
+
+func receive(t *testing.T, msgs <-chan string) string {
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(time.Second):
+		t.Error("no syslog message received")
+		return ""
+	}
+}
+
+func TestLogger(t *testing.T) {
+
+	// Test the logger meets the interface
+	var _ log.Logger = new(Logger)
+
+	addr, msgs := listen(t, "udp", "127.0.0.1:0")
+	lgr, err := New(Config{Network: "udp", Address: addr, Hostname: "myhost", AppName: "myapp"})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	// Make sure levels are working
+	lgr.Debug("test debug")
+	lgr.Info("test info")
+	msg := receive(t, msgs)
+	assert.Regexp(t, regexp.MustCompile(`^<14>1 \S+ myhost myapp \d+ - - test info$`), msg)
+
+	// Test all levels
+	lgr.Level(levels.Trace)
+
+	lgr.Trace("test trace")
+	assert.Contains(t, receive(t, msgs), `<15>1 `)
+
+	lgr.Debugf("Hello %s", "World")
+	assert.Contains(t, receive(t, msgs), ` - - Hello World`)
+
+	lgr.Warn("foo bar", log.Map{"baz": "qux", "a b": `"x]\`})
+	assert.Regexp(t, regexp.MustCompile(`^<12>1 .* - \[fields@32473 a_b="\\"x\\]\\\\" baz="qux"\] foo bar$`), receive(t, msgs))
+
+	lgr.Errorf("Hello %s", "World", log.Map{"baz": "qux"})
+	assert.Contains(t, receive(t, msgs), `[fields@32473 baz="qux"] Hello World`)
+
+	assert.PanicsWithValue(t, "test panic", func() { lgr.Panic("test panic") })
+	assert.Contains(t, receive(t, msgs), `<10>1 `)
+
+	// Test the prefix is used as APP-NAME
+	lgr.Named("sub").Error("test error")
+	assert.Contains(t, receive(t, msgs), ` myhost sub `)
+}
+
+func TestRFC3164(t *testing.T) {
+	addr, msgs := listen(t, "udp", "127.0.0.1:0")
+	lgr, err := New(Config{Network: "udp", Address: addr, Format: RFC3164, Facility: Local0, Hostname: "myhost"})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Prefix("myapp")
+	lgr.Warn("foo bar", log.Map{"baz": "qux"})
+	assert.Regexp(t, regexp.MustCompile(`^<132>\w{3} [ \d]\d \d\d:\d\d:\d\d myhost myapp\[\d+\]: foo bar baz=qux$`), receive(t, msgs))
+}
+
+func TestTCP(t *testing.T) {
+	addr, msgs := listen(t, "tcp", "127.0.0.1:0")
+	lgr, err := New(Config{Network: "tcp", Address: addr})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Info("foo")
+	lgr.Info("bar")
+	assert.True(t, strings.HasSuffix(receive(t, msgs), " - - foo"))
+	assert.True(t, strings.HasSuffix(receive(t, msgs), " - - bar"))
+
+	// Test reconnection once the connection was lost
+	assert.NoError(t, lgr.writer.conn.Close())
+	lgr.Info("baz")
+	assert.True(t, strings.HasSuffix(receive(t, msgs), " - - baz"))
+}
+
+func TestOctetCounting(t *testing.T) {
+	addr, msgs := listen(t, "tcp", "127.0.0.1:0")
+	lgr, err := New(Config{Network: "tcp", Address: addr, OctetCounting: true})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Info("foo\n") // newline is allowed within an octet counted message
+	assert.Regexp(t, regexp.MustCompile(`^\d+ <14>1 .* - - foo$`), receive(t, msgs))
+}
+
+func TestUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	_, msgs := listen(t, "unixgram", path)
+	lgr, err := New(Config{Network: "unixgram", Address: path})
+	assert.NoError(t, err)
+	defer lgr.Close()
+
+	lgr.Info("foo")
+	assert.True(t, strings.HasSuffix(receive(t, msgs), " - - foo"))
+}
@@ -0,0 +1,245 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Usual locations of the local syslog daemon unix socket.
+var unixSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Severity levels of RFC5424, section 6.2.1.
+const (
+	sevEmergency = iota
+	sevAlert
+	sevCritical
+	sevError
+	sevWarning
+	sevNotice
+	sevInformational
+	sevDebug
+)
+
+// severity maps a levels.Type to a syslog severity.
+func severity(level levels.Type) int {
+	switch level {
+	case levels.Fatal:
+		return sevAlert
+	case levels.Panic:
+		return sevCritical
+	case levels.Error:
+		return sevError
+	case levels.Warn:
+		return sevWarning
+	case levels.Info:
+		return sevInformational
+	default: // levels.Debug, levels.Trace
+		return sevDebug
+	}
+}
+
+// writer holds the connection to the syslog daemon, shared by all the
+// loggers derived from one another. It reconnects when writing fails.
+type writer struct {
+	Config
+
+	mu   sync.Mutex
+	conn net.Conn
+	pid  int
+}
+
+func newWriter(cfg Config) (*writer, error) {
+	w := &writer{
+		Config: cfg,
+		pid:    os.Getpid(),
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect dials the syslog daemon. It must be called with the lock held.
+func (w *writer) connect() error {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if w.Network == "" && w.Address == "" {
+		for _, network := range []string{"unixgram", "unix"} {
+			for _, path := range unixSockets {
+				if conn, err := net.DialTimeout(network, path, w.Timeout); err == nil {
+					w.conn = conn
+					w.Network = network
+					w.Address = path
+					return nil
+				}
+			}
+		}
+		return errors.New("syslog: local syslog daemon not found")
+	}
+	network := w.Network
+	if network == "" {
+		network = "unixgram"
+	}
+	conn, err := net.DialTimeout(network, w.Address, w.Timeout)
+	if err != nil {
+		return fmt.Errorf("syslog: %w", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// stream reports whether the connection is stream oriented, requiring
+// messages to be framed.
+func (w *writer) stream() bool {
+	switch w.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// write sends a message, reconnecting once if the connection was lost.
+func (w *writer) write(msg string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stream() {
+		if w.OctetCounting {
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		} else {
+			msg += "\n"
+		}
+	}
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				continue
+			}
+		}
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.Timeout))
+		if _, err = w.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// close closes the connection to the syslog daemon.
+func (w *writer) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// format renders a message in the configured format.
+func (w *writer) format(t time.Time, level levels.Type, appName, msg string, fields log.Map) string {
+	pri := int(w.Facility)*8 + severity(level)
+	switch w.Format {
+	case RFC3164:
+		tag := appName
+		if tag == "" {
+			tag = "-"
+		}
+		return fmt.Sprintf("<%d>%s %s %s[%d]: %s%s",
+			pri, t.Format(time.Stamp), w.Hostname, tag, w.pid, msg, appended(fields))
+	default:
+		return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s",
+			pri, t.Format("2006-01-02T15:04:05.000000Z07:00"), header(w.Hostname, 255), header(appName, 48), w.pid,
+			structured(w.StructuredDataID, fields), msg)
+	}
+}
+
+// header returns a valid RFC5424 header field value, or the nil value "-".
+func header(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// structured renders the fields as an RFC5424 structured data element, or
+// the nil value "-".
+func structured(id string, fields log.Map) string {
+	if len(fields) == 0 {
+		return "-"
+	}
+	var b strings.Builder
+	b.WriteString("[" + id)
+	for _, key := range sorted(fields) {
+		b.WriteString(" " + paramName(key) + `="` + paramValue(fields[key]) + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// paramName returns a valid RFC5424 PARAM-NAME, i.e. 1 to 32 printable
+// US-ASCII characters except '=', SP, ']' and '"'.
+func paramName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// paramValue escapes '"', '\' and ']' in a RFC5424 PARAM-VALUE.
+func paramValue(v interface{}) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fmt.Sprint(v))
+}
+
+// appended renders the fields as key=value pairs appended to the message.
+func appended(fields log.Map) string {
+	var ret string
+	for _, key := range sorted(fields) {
+		ret += fmt.Sprintf(" %s=%v", key, fields[key])
+	}
+	return ret
+}
+
+func sorted(fields log.Map) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,15 +1,50 @@
 package logger
 
 import (
+	"context"
 	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/interface/otel"
+	"gorm.io/gorm/logger"
 )
 
+// Config mirrors gorm's logger.Config, with extra options.
 type Config struct {
-	IgnoreRecordNotFoundError bool          // Ignore ErrRecordNotFound Error
-	SlowThreshold             time.Duration // Threshold for reporting slow SQL queries
+	IgnoreRecordNotFoundError bool            // Ignore ErrRecordNotFound Error
+	SlowThreshold             time.Duration   // Threshold for reporting slow SQL queries
+	Colorful                  bool            // Colorize messages and fields, as gorm does
+	LogLevel                  logger.LogLevel // Initial log level, default: the one of the underlying logger
+
+	// Fields added from the context given to gorm, i.e. with db.WithContext,
+	// to tie queries back to the requests which triggered them.
+	ContextKeys map[string]interface{}            // Field names to context keys, default: requestid and userid
+	ContextFunc func(ctx context.Context) log.Map // Extra fields, default: otel.Fields (trace_id and span_id)
+
+	// Log the parameterised statements, with their bind variables in a separate
+	// "vars" field, rather than the interpolated ones. It requires registering
+	// the logger as a plugin, i.e. db.Use(lgr), with gorm versions which do
+	// not call ParamsFilter; without it, a warning is logged once and queries
+	// are logged with their bind variables.
+	ParameterizedQueries bool
+	RedactColumns        map[string]RedactFunc // Redaction rules of the bind variables, per lowercase column name
+
+	// Aggregate the statistics of the queries per fingerprint, whatever the
+	// log level, see Logger.Stats. Their summary is logged at Info every
+	// StatsInterval, for the StatsTop slowest queries, until Logger.Close,
+	// which should be called once the gorm DB is no longer used.
+	Stats         bool
+	StatsInterval time.Duration // Interval of the summary, default: 0, none
+	StatsTop      int           // Number of queries of the summary, default: 10
 }
 
 var defaultConfig = Config{
 	IgnoreRecordNotFoundError: false,
 	SlowThreshold:             200 * time.Millisecond,
+	ContextKeys: map[string]interface{}{
+		"requestid": "requestid", // i.e. fiber locals, with db.WithContext(c.Context())
+		"userid":    "userid",
+	},
+	ContextFunc: otel.Fields,
+	StatsTop:    10,
 }
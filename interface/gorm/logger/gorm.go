@@ -4,59 +4,190 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/roninzo/log"
-	"github.com/roninzo/log/levels"
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-	"gorm.io/gorm/utils"
 )
 
 // Gorm logger via composition.
 type Logger struct {
-	logger log.Logger // Underlying Logger instance.
-	Config            // Extra Gorm config.
+	logger log.Logger      // Underlying Logger instance.
+	Config                 // Extra Gorm config.
+	mode   logger.LogLevel // Gorm log level, 0 to follow the underlying logger.
+	stats  *stats          // Statistics of the queries, shared with children.
+	params *params         // Parameterisation of the queries, shared with children.
 }
 
+// params tracks how the queries are parameterised, for ParameterizedQueries.
+type params struct {
+	filtered int32     // set once gorm calls ParamsFilter
+	once     sync.Once // warns once the queries are not parameterised
+}
+
+// Make sure the logger meets gorm's interfaces.
+var (
+	_ logger.Interface = (*Logger)(nil)
+	_ gorm.Plugin      = (*Logger)(nil)
+)
+
 // Constructor.
-func New(lgr log.Logger, configs ...Config) Interface {
+func New(lgr log.Logger, configs ...Config) *Logger {
 	config := defaultConfig
 	if len(configs) > 0 {
 		config = configs[0]
+		if config.ContextKeys == nil {
+			config.ContextKeys = defaultConfig.ContextKeys
+		}
+		if config.ContextFunc == nil {
+			config.ContextFunc = defaultConfig.ContextFunc
+		}
+		if config.StatsTop <= 0 {
+			config.StatsTop = defaultConfig.StatsTop
+		}
+	}
+	l := &Logger{logger: lgr, Config: config, params: &params{}}
+	if config.LogLevel != 0 {
+		l.mode = toMode(config.LogLevel)
+	}
+	if config.Stats {
+		l.stats = newStats()
+		if config.StatsInterval > 0 {
+			go l.summarize()
+			runtime.SetFinalizer(l, (*Logger).Close) // in case Close is not called
+		}
+	}
+	return l
+}
+
+// ParamsFilter leaves the bind variables out of the queries when
+// ParameterizedQueries is set, with gorm versions which call it.
+func (l Logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.ParameterizedQueries {
+		if l.params != nil {
+			atomic.StoreInt32(&l.params.filtered, 1)
+		}
+		return sql, nil
+	}
+	return sql, params
+}
+
+// withQuery adds the fingerprint of the query to fields, and replaces the
+// query with the parameterised statement and its bind variables when
+// ParameterizedQueries is set.
+func (l Logger) withQuery(ctx context.Context, fields log.Map) log.Map {
+	if l.ParameterizedQueries && ctx != nil {
+		if stmt, ok := ctx.Value(stmtKey{}).(*gorm.Statement); ok {
+			sql := stmt.SQL.String()
+			fields["query"] = sql
+			fields["vars"] = l.bindVars(sql, stmt.Vars)
+		} else {
+			l.unparameterized()
+		}
+	}
+	if sql, ok := fields["query"].(string); ok {
+		fields["fingerprint"] = Fingerprint(sql)
+	}
+	return fields
+}
+
+// unparameterized warns once that the queries are logged with their bind
+// variables despite ParameterizedQueries, gorm neither calling ParamsFilter
+// nor the plugin callbacks, i.e. without db.Use(lgr).
+func (l Logger) unparameterized() {
+	if l.params == nil || atomic.LoadInt32(&l.params.filtered) != 0 {
+		return
+	}
+	l.params.once.Do(func() {
+		l.logger.Warn("gorm logger: ParameterizedQueries requires the logger to be registered as a plugin, i.e. db.Use(lgr): queries are logged with their bind variables", log.Map{"source": source()})
+	})
+}
+
+// withContext adds the fields of the context to fields.
+func (l Logger) withContext(ctx context.Context, fields log.Map) log.Map {
+	if ctx == nil {
+		return fields
 	}
-	return &Logger{lgr, config}
+	for name, key := range l.ContextKeys {
+		if val := ctx.Value(key); val != nil {
+			fields[name] = val
+		}
+	}
+	if l.ContextFunc != nil {
+		for name, val := range l.ContextFunc(ctx) {
+			fields[name] = val
+		}
+	}
+	return fields
 }
 
 // Info print info.
 func (l Logger) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Info(fmt.Sprintf(msg, data...), log.Map{"source": utils.FileWithLineNum()})
+	if l.logLevel() >= logger.Info {
+		l.logger.Info(l.colorize(logger.Green, fmt.Sprintf(msg, data...), l.withContext(ctx, log.Map{"source": source()})))
+	}
 }
 
 // Warn print warn messages.
 func (l Logger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Warn(fmt.Sprintf(msg, data...), log.Map{"source": utils.FileWithLineNum()})
+	if l.logLevel() >= logger.Warn {
+		l.logger.Warn(l.colorize(logger.Magenta, fmt.Sprintf(msg, data...), l.withContext(ctx, log.Map{"source": source()})))
+	}
 }
 
 // Error print error messages.
 func (l Logger) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Error(fmt.Sprintf(msg, data...), log.Map{"source": utils.FileWithLineNum()})
+	if l.logLevel() >= logger.Error {
+		l.logger.Error(l.colorize(logger.Red, fmt.Sprintf(msg, data...), l.withContext(ctx, log.Map{"source": source()})))
+	}
 }
 
 // Trace print sql message.
 func (l Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
-	if l.logger.Level() >= levels.Silent { // Skip Silent
+	elapsed := time.Since(begin)
+	slow := elapsed > l.SlowThreshold && l.SlowThreshold != 0
+	fc = memoize(fc) // shared by the statistics and the entry
+	if l.stats != nil {
+		l.addStats(ctx, fc, elapsed, slow)
+	}
+	level := l.logLevel()
+	if level <= logger.Silent { // Skip Silent
 		return
 	}
-	elapsed := time.Since(begin)
 	switch {
-	case err != nil && l.logger.Level() <= levels.Error && (!errors.Is(err, logger.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError): // Error/Warn/Info
+	case err != nil && level >= logger.Error && (!errors.Is(err, logger.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError): // Error/Warn/Info
 		sql, rows := fc()
-		l.logger.Error(traceErrMsg(utils.FileWithLineNum(), sql, rows, elapsed, err))
-	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.logger.Level() <= levels.Warn: // Warn/Info
+		msg, fields := traceErrMsg(source(), sql, rows, elapsed, err)
+		l.logger.Error(l.colorize(logger.RedBold, msg, l.withContext(ctx, l.withQuery(ctx, fields))))
+	case slow && level >= logger.Warn: // Warn/Info
 		sql, rows := fc()
-		l.logger.Warn(traceWarnMsg(utils.FileWithLineNum(), sql, rows, elapsed, l.SlowThreshold))
-	case l.logger.Level() == levels.Info: // Info
+		msg, fields := traceWarnMsg(source(), sql, rows, elapsed, l.SlowThreshold)
+		l.logger.Warn(l.colorize(logger.RedBold, msg, l.withContext(ctx, l.withQuery(ctx, fields))))
+	case level >= logger.Info: // Info
 		sql, rows := fc()
-		l.logger.Info(traceMsg(utils.FileWithLineNum(), sql, rows, elapsed))
+		msg, fields := traceMsg(source(), sql, rows, elapsed)
+		l.logger.Info(l.colorize(logger.Green, msg, l.withContext(ctx, l.withQuery(ctx, fields))))
+	}
+}
+
+// colorize colors the message and the main fields, as gorm does, when
+// Colorful is set.
+func (l Logger) colorize(color, msg string, fields log.Map) (string, log.Map) {
+	if !l.Colorful {
+		return msg, fields
+	}
+	for key, color := range map[string]string{
+		"source": logger.Green,
+		"time":   logger.Yellow,
+		"rows":   logger.BlueBold,
+	} {
+		if val, ok := fields[key].(string); ok {
+			fields[key] = color + val + logger.Reset
+		}
 	}
+	return color + msg + logger.Reset, fields
 }
@@ -0,0 +1,342 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	stdlog "log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// ctxKey is a non-string context key.
+type ctxKey struct{}
+
+func TestContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	l := New(lgr)
+
+	ctx := context.WithValue(context.Background(), "requestid", "rid-1") // as fiber locals
+	ctx = context.WithValue(ctx, "userid", "john")
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Contains(t, buf.String(), "[requestid=rid-1]")
+	assert.Contains(t, buf.String(), "[userid=john]")
+	assert.Contains(t, buf.String(), "[query=SELECT 1]")
+
+	buf.Reset()
+	l.Error(ctx, "failed %s", "foo")
+	assert.Contains(t, buf.String(), "failed foo")
+	assert.Contains(t, buf.String(), "[requestid=rid-1]")
+
+	// Test configured keys and extra fields
+	buf.Reset()
+	l = New(lgr, Config{
+		ContextKeys: map[string]interface{}{"tenant": ctxKey{}},
+		ContextFunc: func(ctx context.Context) log.Map { return log.Map{"trace_id": "abc"} },
+	})
+	l.Trace(context.WithValue(ctx, ctxKey{}, "acme"), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, errors.New("boom"))
+	assert.Contains(t, buf.String(), "[ERROR] trace boom")
+	assert.Contains(t, buf.String(), "[tenant=acme]")
+	assert.Contains(t, buf.String(), "[trace_id=abc]")
+	assert.NotContains(t, buf.String(), "[requestid=")
+
+	// Test without context values
+	buf.Reset()
+	l.Warn(context.Background(), "careful")
+	assert.Contains(t, buf.String(), "careful")
+	assert.NotContains(t, buf.String(), "[tenant=")
+}
+
+// dialector is a fake gorm dialector, for dry runs.
+type dialector struct{}
+
+func (dialector) Name() string { return "fake" }
+func (dialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+func (dialector) Migrator(db *gorm.DB) gorm.Migrator                          { return nil }
+func (dialector) DataTypeOf(*schema.Field) string                             { return "" }
+func (dialector) DefaultValueOf(*schema.Field) clause.Expression              { return clause.Expr{} }
+func (dialector) BindVarTo(w clause.Writer, _ *gorm.Statement, _ interface{}) { _ = w.WriteByte('?') }
+func (dialector) QuoteTo(w clause.Writer, s string)                           { _, _ = w.WriteString("`" + s + "`") }
+func (dialector) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+type user struct {
+	ID    uint
+	Name  string
+	Email string
+}
+
+func TestParameterizedQueries(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	l := New(lgr, Config{
+		ParameterizedQueries: true,
+		RedactColumns:        map[string]RedactFunc{"email": Redact},
+	})
+	db, err := gorm.Open(dialector{}, &gorm.Config{Logger: l, DryRun: true})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(l))
+
+	db.Where("email = ? AND name = ?", "john@example.com", "john").Find(&[]user{})
+	assert.Contains(t, buf.String(), "[query=SELECT * FROM `users` WHERE email = ? AND name = ?]")
+	assert.Contains(t, buf.String(), "[vars=[[REDACTED] john]]")
+	assert.Regexp(t, `\[source=\S+/gorm_test\.go:\d+\]`, buf.String())
+	assert.NotContains(t, buf.String(), "john@example.com")
+	fingerprint := Fingerprint("SELECT * FROM `users` WHERE email = ? AND name = ?")
+	assert.Contains(t, buf.String(), "[fingerprint="+fingerprint+"]")
+
+	buf.Reset()
+	db.Create(&user{Name: "jane", Email: "jane@example.com"})
+	assert.Contains(t, buf.String(), "[query=INSERT INTO `users` (`name`,`email`) VALUES (?,?)]")
+	assert.Contains(t, buf.String(), "[vars=[jane [REDACTED]]]")
+
+	// Test the missing plugin is warned about once
+	buf.Reset()
+	l = New(lgr, Config{ParameterizedQueries: true})
+	db, err = gorm.Open(dialector{}, &gorm.Config{Logger: l, DryRun: true})
+	assert.NoError(t, err)
+	db.Where("email = ?", "john@example.com").Find(&[]user{})
+	db.Debug().Where("email = ?", "john@example.com").Find(&[]user{})
+	assert.Equal(t, 1, strings.Count(buf.String(), "[WARN]  gorm logger: ParameterizedQueries requires the logger to be registered as a plugin"), "once, children included")
+	assert.Contains(t, buf.String(), "[query=SELECT * FROM `users` WHERE email = 'john@example.com']")
+
+	// Test the interpolated query without the option
+	buf.Reset()
+	db.Logger = New(lgr)
+	db.Where("email = ?", "john@example.com").Find(&[]user{})
+	assert.Contains(t, buf.String(), "[query=SELECT * FROM `users` WHERE email = 'john@example.com']")
+	assert.Contains(t, buf.String(), "[fingerprint="+Fingerprint("SELECT * FROM `users` WHERE email = ?")+"]")
+	assert.NotContains(t, buf.String(), "[vars=")
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"SELECT * FROM users WHERE `email` = ? AND age > ?", []string{"email", "age"}},
+		{`SELECT * FROM users WHERE "id" IN ($1,$2) AND name LIKE $3`, []string{"id", "id", "name"}},
+		{"SELECT * FROM users WHERE age BETWEEN @p1 AND @p2", []string{"age", "age"}},
+		{"INSERT INTO `users` (`name`,`email`) VALUES (?,?),(?,?)", []string{"name", "email", "name", "email"}},
+		{"UPDATE users SET name=?,updated_at=? WHERE id = ?", []string{"name", "updated_at", "id"}},
+		{"SELECT coalesce(?, 1)", []string{""}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, columns(tt.sql), tt.sql)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM `users` WHERE email = 'john@example.com' AND id = 42", "select * from `users` where email = ? and id = ?"},
+		{"select *\n  from `users`\twhere email = ? and id = ?;", "select * from `users` where email = ? and id = ?"},
+		{"SELECT * FROM t1 WHERE id IN (1, 2, 3)", "select * from t1 where id in (...)"},
+		{`SELECT * FROM t1 WHERE id IN ($1,$2) AND s = 'it''s'`, "select * from t1 where id in (...) and s = ?"},
+		{"INSERT INTO users (name) VALUES (?),(?),(?)", "insert into users (name) values (...)"},
+		{`SELECT "Mixed" FROM t WHERE x = -1.5`, `select "Mixed" from t where x = -?`},
+		{`SELECT "oops`, `select "oops`},
+		{"SELECT `oops", "select `oops"},
+		{"SELECT 'oops", "select ?"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Normalize(tt.sql), tt.sql)
+	}
+	assert.Equal(t, Fingerprint("SELECT 1"), Fingerprint("select   2"))
+	assert.Len(t, Fingerprint("SELECT 1"), 16)
+}
+
+func TestLogMode(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	l := New(lgr)
+	query := func() (string, int64) { return "SELECT 1", 1 }
+
+	// Test the child level does not leak into its parent
+	child := l.LogMode(logger.Error)
+	child.Trace(context.Background(), time.Now(), query, nil)
+	child.Info(context.Background(), "info")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, levels.Info, lgr.Level())
+	l.Trace(context.Background(), time.Now(), query, nil)
+	assert.Contains(t, buf.String(), "[query=SELECT 1]")
+
+	// Test a child only restricts the verbosity of the underlying logger
+	buf.Reset()
+	lgr.Level(levels.Warn)
+	child = l.LogMode(logger.Info)
+	child.Trace(context.Background(), time.Now(), query, nil)
+	child.Info(context.Background(), "info")
+	assert.Empty(t, buf.String())
+	child.Warn(context.Background(), "warn")
+	assert.Contains(t, buf.String(), "[WARN]  warn")
+	assert.Equal(t, levels.Warn, lgr.Level())
+	lgr.Level(levels.Info)
+
+	// Test the levels, including unknown ones
+	tests := []struct {
+		level logger.LogLevel
+		want  []bool // error, warn, info
+	}{
+		{logger.Silent, []bool{false, false, false}},
+		{logger.Error, []bool{true, false, false}},
+		{logger.Warn, []bool{true, true, false}},
+		{logger.Info, []bool{true, true, true}},
+		{0, []bool{false, false, false}},
+		{logger.Info + 1, []bool{true, true, true}},
+	}
+	for _, tt := range tests {
+		child = l.LogMode(tt.level)
+		for i, f := range []func(context.Context, string, ...interface{}){child.Error, child.Warn, child.Info} {
+			buf.Reset()
+			f(context.Background(), "msg")
+			assert.Equal(t, tt.want[i], buf.Len() > 0, "level %d, method %d", tt.level, i)
+		}
+	}
+
+	// Test the initial level from the config
+	buf.Reset()
+	New(lgr, Config{LogLevel: logger.Silent}).Error(context.Background(), "error")
+	assert.Empty(t, buf.String())
+}
+
+func TestColorful(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	New(lgr, Config{Colorful: true}).Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	assert.Contains(t, buf.String(), logger.Green+log.MesgGormTrace+logger.Reset)
+	assert.Contains(t, buf.String(), "[rows="+logger.BlueBold+"1"+logger.Reset+"]")
+}
+
+func TestParamsFilter(t *testing.T) {
+	sql, vars := New(nil, Config{ParameterizedQueries: true}).ParamsFilter(context.Background(), "SELECT ?", 1)
+	assert.Equal(t, "SELECT ?", sql)
+	assert.Nil(t, vars)
+	_, vars = New(nil).ParamsFilter(context.Background(), "SELECT ?", 1)
+	assert.Equal(t, []interface{}{1}, vars)
+}
+
+func TestStats(t *testing.T) {
+	buf := &syncBuffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	l := New(lgr, Config{Stats: true, SlowThreshold: 50 * time.Millisecond, LogLevel: logger.Silent})
+	trace := func(sql string, rows int64, elapsed time.Duration) {
+		l.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) { return sql, rows }, nil)
+	}
+
+	// Test the queries are aggregated per fingerprint, whatever the level
+	for i := 1; i <= 20; i++ {
+		trace(fmt.Sprintf("SELECT * FROM users WHERE id = %d", i), 1, time.Duration(i)*10*time.Millisecond)
+	}
+	trace("SELECT 1", 0, time.Millisecond)
+	assert.Empty(t, buf.String())
+	calls := 0
+	l.LogMode(logger.Info).Trace(context.Background(), time.Now(), func() (string, int64) { calls++; return "SELECT 2", 0 }, nil)
+	assert.Equal(t, 1, calls, "shared by the statistics and the entry")
+	stats := l.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, Fingerprint("SELECT * FROM users WHERE id = 1"), stats[0].Fingerprint)
+	assert.Equal(t, "select * from users where id = ?", stats[0].Query)
+	assert.Equal(t, uint64(20), stats[0].Count)
+	assert.Equal(t, uint64(16), stats[0].Slow)
+	assert.InDelta(t, 100*time.Millisecond, stats[0].P50, float64(5*time.Millisecond))
+	assert.InDelta(t, 190*time.Millisecond, stats[0].P95, float64(5*time.Millisecond))
+	assert.InDelta(t, 200*time.Millisecond, stats[0].Max, float64(5*time.Millisecond))
+	assert.Equal(t, int64(20), stats[0].Rows)
+	assert.Equal(t, uint64(2), stats[1].Count, "shared with children")
+
+	// Test the parameterised statement is aggregated without interpolating it
+	stmt := &gorm.Statement{DB: &gorm.DB{}}
+	stmt.SQL.WriteString("SELECT * FROM users WHERE id = ?")
+	stmt.RowsAffected = 1
+	ctx := context.WithValue(context.Background(), stmtKey{}, stmt)
+	l.Trace(ctx, time.Now(), func() (string, int64) { calls++; return "SELECT * FROM users WHERE id = 21", 1 }, nil)
+	assert.Equal(t, 1, calls, "not called when nothing is logged")
+	assert.Equal(t, uint64(21), l.Stats()[0].Count)
+	assert.Equal(t, int64(21), l.Stats()[0].Rows)
+
+	// Test the periodic summary
+	buf.Reset()
+	l.StatsInterval, l.StatsTop = 10*time.Millisecond, 1
+	go l.summarize()
+	assert.Eventually(t, func() bool { return strings.Contains(buf.String(), log.MesgGormStats) }, time.Second, 10*time.Millisecond)
+	assert.NoError(t, l.Close())
+	assert.NoError(t, l.Close())
+	assert.Contains(t, buf.String(), "[fingerprint="+stats[0].Fingerprint+"]")
+	assert.Contains(t, buf.String(), "[count=%!s(uint64=21)]")
+	assert.NotContains(t, buf.String(), "[fingerprint="+stats[1].Fingerprint+"]")
+
+	l.ResetStats()
+	assert.Empty(t, l.Stats())
+	assert.Nil(t, New(lgr).Stats())
+
+	// Test the summary stops once the logger is garbage collected
+	done := New(lgr, Config{Stats: true, StatsInterval: time.Hour}).stats.done
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), percentile(samples, 50))
+	assert.Equal(t, time.Duration(10), percentile(samples, 95))
+	assert.Equal(t, time.Duration(1), percentile(samples[:1], 50))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
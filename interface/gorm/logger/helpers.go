@@ -2,6 +2,9 @@ package logger
 
 import (
 	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/roninzo/log"
@@ -48,3 +51,49 @@ func getRows(rows int64) string {
 func getLatency(elapsed time.Duration) string {
 	return fmt.Sprintf("%fms", float64(elapsed.Nanoseconds())/1e6)
 }
+
+// sourcePackages are the packages skipped by source, as callers of the
+// queries: gorm, database/sql and the adapters of this module.
+var sourcePackages = []string{
+	"gorm.io/",
+	"database/sql.",
+	"github.com/roninzo/log/interface/",
+}
+
+// source returns the file name and line number of the caller of the query,
+// as gorm's utils.FileWithLineNum does, skipping sourcePackages but tests.
+func source() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		skip := false
+		for _, pkg := range sourcePackages {
+			if strings.HasPrefix(frame.Function, pkg) {
+				skip = !strings.HasSuffix(frame.File, "_test.go")
+				break
+			}
+		}
+		if !skip {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// memoize returns a function calling fc once, on its first call, and returning
+// its results then on, since fc may interpolate or explain the query.
+func memoize(fc func() (string, int64)) func() (string, int64) {
+	var sql string
+	var rows int64
+	called := false
+	return func() (string, int64) {
+		if !called {
+			sql, rows = fc()
+			called = true
+		}
+		return sql, rows
+	}
+}
@@ -5,26 +5,50 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// LogMode log mode
-func (l *Logger) LogMode(level logger.LogLevel) Interface {
-	derefdLogger := *l
-	newlogger := &derefdLogger
-	newlogger.logger.Level(toInternalLevel(level))
-	return newlogger
+// LogMode returns a child logger with the given log level, leaving the level
+// of its parent, and of the underlying log.Logger, unchanged. The entries keep
+// their own level, so a child only restricts the verbosity: a level more
+// verbose than the one of the underlying log.Logger has no effect, i.e.
+// db.Debug() over a Warn logger does not log every query.
+func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
+	child := *l
+	child.mode = toMode(level)
+	return &child
 }
 
-// Converts logger.LogLevel to levels.Type.
-func toInternalLevel(level logger.LogLevel) levels.Type {
-	switch level {
-	case logger.Silent:
-		return levels.Silent
-	case logger.Error:
-		return levels.Error
-	case logger.Warn:
-		return levels.Warn
-	case logger.Info:
-		return levels.Info
+// logLevel returns the gorm log level: the one of the underlying logger,
+// restricted to the one set by LogMode or Config.
+func (l Logger) logLevel() logger.LogLevel {
+	level := fromInternalLevel(l.logger.Level())
+	if l.mode != 0 && l.mode < level {
+		return l.mode
+	}
+	return level
+}
+
+// Clamps a gorm log level, as gorm does: levels below Silent are silent, and
+// levels above Info log everything.
+func toMode(level logger.LogLevel) logger.LogLevel {
+	switch {
+	case level < logger.Silent:
+		return logger.Silent
+	case level > logger.Info:
+		return logger.Info
 	default:
-		return levels.Info
+		return level
+	}
+}
+
+// Converts levels.Type to logger.LogLevel.
+func fromInternalLevel(level levels.Type) logger.LogLevel {
+	switch {
+	case level >= levels.Silent:
+		return logger.Silent
+	case level >= levels.Error: // Error, Panic and Fatal
+		return logger.Error
+	case level == levels.Warn:
+		return logger.Warn
+	default: // Trace, Debug and Info
+		return logger.Info
 	}
 }
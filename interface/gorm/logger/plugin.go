@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// stmtKey is the context key of the statement being traced.
+type stmtKey struct{}
+
+// Name implements gorm.Plugin.
+func (l *Logger) Name() string {
+	return "github.com/roninzo/log/interface/gorm/logger"
+}
+
+// Initialize implements gorm.Plugin, registering callbacks which make the
+// statements available to Trace, for ParameterizedQueries.
+func (l *Logger) Initialize(db *gorm.DB) error {
+	name := l.Name() + ":statement"
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register(name, withStatement),
+		callbacks.Query().Before("*").Register(name, withStatement),
+		callbacks.Update().Before("*").Register(name, withStatement),
+		callbacks.Delete().Before("*").Register(name, withStatement),
+		callbacks.Row().Before("*").Register(name, withStatement),
+		callbacks.Raw().Before("*").Register(name, withStatement),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withStatement stores the statement in its own context.
+func withStatement(db *gorm.DB) {
+	stmt := db.Statement
+	if stmt.Context == nil {
+		stmt.Context = context.Background()
+	}
+	if s, ok := stmt.Context.Value(stmtKey{}).(*gorm.Statement); !ok || s != stmt { // avoid nesting
+		stmt.Context = context.WithValue(stmt.Context, stmtKey{}, stmt)
+	}
+}
+
+// ContextWithQuery returns a context carrying a parameterised statement and
+// its bind variables, for ParameterizedQueries, to trace queries not run by
+// gorm, i.e. by database/sql drivers.
+func ContextWithQuery(ctx context.Context, sql string, vars []interface{}) context.Context {
+	stmt := &gorm.Statement{Vars: vars}
+	stmt.SQL.WriteString(sql)
+	return context.WithValue(ctx, stmtKey{}, stmt)
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/roninzo/log"
+)
+
+// Redacted replaces the bind variables redacted by Redact.
+const Redacted = log.Redacted
+
+// RedactFunc is a redaction rule of the bind variables of a column.
+type RedactFunc func(value interface{}) interface{}
+
+// Redact is a RedactFunc replacing values with Redacted.
+func Redact(value interface{}) interface{} {
+	return Redacted
+}
+
+var (
+	// placeholder matches the bind variables placeholders of the dialects,
+	// i.e. "?" (MySQL, SQLite), "$1" (PostgreSQL) and "@p1" (SQL Server).
+	placeholder = regexp.MustCompile(`\?|\$\d+|@p\d+`)
+
+	// operand matches the column of the placeholder which ends a statement,
+	// i.e. "WHERE `email` = ?" or "id IN (?,?".
+	operand = regexp.MustCompile("(\\w+)[`\"\\]]?\\s*(?:=|<>|!=|<=|>=|<|>|(?i:not\\s+)?(?i:i?like|in|between))\\s*\\(?\\s*(?:(?:\\?|\\$\\d+|@p\\d+)\\s*(?:,|(?i:and))\\s*)*$")
+
+	// insert matches the columns of an insert statement.
+	insert = regexp.MustCompile(`(?is)^\s*insert\s+into\s+\S+\s*\(([^)]*)\)\s*values`)
+
+	// lists match the lists of placeholders, and the lists of such lists.
+	list  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	lists = regexp.MustCompile(`\(\.\.\.\)(?:\s*,\s*\(\.\.\.\))+`)
+)
+
+// columns guesses the columns of the bind variables of a parameterised
+// statement, "" when unknown.
+func columns(sql string) []string {
+	locs := placeholder.FindAllStringIndex(sql, -1)
+	cols := make([]string, len(locs))
+	var values []string // columns of an insert statement
+	if m := insert.FindStringSubmatch(sql); m != nil {
+		for _, col := range strings.Split(m[1], ",") {
+			values = append(values, strings.Trim(strings.TrimSpace(col), "`\"[]"))
+		}
+	}
+	for i, loc := range locs {
+		if m := operand.FindStringSubmatch(sql[:loc[0]]); m != nil {
+			cols[i] = m[1]
+		} else if len(values) > 0 {
+			cols[i] = values[i%len(values)]
+		}
+	}
+	return cols
+}
+
+// bindVars returns the bind variables of a parameterised statement, redacted
+// as per RedactColumns.
+func (l Logger) bindVars(sql string, vars []interface{}) []interface{} {
+	ret := make([]interface{}, len(vars))
+	copy(ret, vars)
+	if len(l.RedactColumns) == 0 {
+		return ret
+	}
+	cols := columns(sql)
+	for i, loc := range placeholder.FindAllStringIndex(sql, -1) {
+		j := i // "?" placeholders are in order, others are numbered
+		if p := sql[loc[0]:loc[1]]; p != "?" {
+			n, _ := strconv.Atoi(strings.TrimLeft(p, "$@p"))
+			j = n - 1
+		}
+		if j < 0 || j >= len(ret) {
+			continue
+		}
+		if redact, ok := l.RedactColumns[strings.ToLower(cols[i])]; ok {
+			ret[j] = redact(vars[j])
+		}
+	}
+	return ret
+}
+
+// Normalize normalises a SQL statement, so that identical queries share the
+// same text whatever their values: literals and placeholders are replaced
+// with "?", lists of them with "(...)", whitespace is collapsed and keywords
+// are lowercased.
+func Normalize(sql string) string {
+	b := &strings.Builder{}
+	space := false
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			space = true
+			i++
+			continue
+		case space && b.Len() > 0:
+			b.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case c == '\'': // string literal
+			i++
+			for i < len(sql) {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' { // escaped quote
+						i += 2
+						continue
+					}
+					break
+				}
+				if sql[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			b.WriteByte('?')
+		case c == '"' || c == '`': // quoted identifier
+			end := len(sql) // unterminated
+			if j := strings.IndexByte(sql[i+1:], c); j >= 0 {
+				end = i + j + 2
+			}
+			b.WriteString(sql[i:end])
+			i = end
+		case c >= '0' && c <= '9', (c == '$' || c == '@') && i+1 < len(sql) && isDigitOrP(sql[i+1]): // number or placeholder
+			j := i + 1
+			for j < len(sql) && (isWord(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+		case isWord(c): // keyword or identifier
+			j := i
+			for j < len(sql) && isWord(sql[j]) {
+				j++
+			}
+			b.WriteString(strings.ToLower(sql[i:j]))
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	s := strings.TrimRight(b.String(), "; ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	s = strings.ReplaceAll(s, " )", ")")
+	s = list.ReplaceAllString(s, "(...)")
+	return lists.ReplaceAllString(s, "(...)")
+}
+
+// Fingerprint returns a short hash of the normalised form of a statement, see
+// Normalize.
+func Fingerprint(sql string) string {
+	sum := sha256.Sum256([]byte(Normalize(sql)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func isWord(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigitOrP(c byte) bool {
+	return c == 'p' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roninzo/log"
+	"gorm.io/gorm"
+)
+
+// Limits of the statistics, bounding their memory usage.
+const (
+	maxFingerprints = 1000 // further queries are not aggregated
+	maxSamples      = 1024 // latencies kept per fingerprint, for percentiles
+)
+
+// QueryStats are the statistics of the queries sharing a fingerprint.
+type QueryStats struct {
+	Fingerprint string        // See Fingerprint
+	Query       string        // Normalised query, see Normalize
+	Count       uint64        // Number of queries
+	Slow        uint64        // Number of queries above SlowThreshold
+	P50         time.Duration // Median latency, over the latest queries
+	P95         time.Duration // 95th percentile latency, over the latest queries
+	Max         time.Duration // Maximum latency
+	Rows        int64         // Total number of rows affected
+}
+
+// stats aggregates the statistics of the queries, per fingerprint.
+type stats struct {
+	mu      sync.Mutex
+	queries map[string]*queryStats
+	done    chan struct{}
+	once    sync.Once
+}
+
+// queryStats are the statistics of a fingerprint being aggregated.
+type queryStats struct {
+	QueryStats
+	samples []time.Duration // ring buffer of the latest latencies
+	next    int
+}
+
+func newStats() *stats {
+	return &stats{
+		queries: map[string]*queryStats{},
+		done:    make(chan struct{}),
+	}
+}
+
+// add aggregates a query.
+func (s *stats) add(sql string, rows int64, elapsed time.Duration, slow bool) {
+	query := Normalize(sql)
+	fingerprint := Fingerprint(query) // normalising is idempotent
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queries[fingerprint]
+	if !ok {
+		if len(s.queries) >= maxFingerprints {
+			return
+		}
+		q = &queryStats{QueryStats: QueryStats{Fingerprint: fingerprint, Query: query}}
+		s.queries[fingerprint] = q
+	}
+	q.Count++
+	if slow {
+		q.Slow++
+	}
+	if elapsed > q.Max {
+		q.Max = elapsed
+	}
+	if rows > 0 {
+		q.Rows += rows
+	}
+	if len(q.samples) < maxSamples {
+		q.samples = append(q.samples, elapsed)
+	} else {
+		q.samples[q.next] = elapsed
+		q.next = (q.next + 1) % maxSamples
+	}
+}
+
+// list returns the statistics, the slowest first by 95th percentile latency.
+func (s *stats) list() []QueryStats {
+	s.mu.Lock()
+	ret := make([]QueryStats, 0, len(s.queries))
+	for _, q := range s.queries {
+		samples := make([]time.Duration, len(q.samples))
+		copy(samples, q.samples)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		qs := q.QueryStats
+		qs.P50 = percentile(samples, 50)
+		qs.P95 = percentile(samples, 95)
+		ret = append(ret, qs)
+	}
+	s.mu.Unlock()
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].P95 != ret[j].P95 {
+			return ret[i].P95 > ret[j].P95
+		}
+		return ret[i].Fingerprint < ret[j].Fingerprint
+	})
+	return ret
+}
+
+func (s *stats) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = map[string]*queryStats{}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if i < 1 {
+		i = 1
+	}
+	return sorted[i-1]
+}
+
+// addStats aggregates a query, by the parameterised statement run by gorm when
+// known, not to interpolate its bind variables through fc when nothing is
+// logged.
+func (l Logger) addStats(ctx context.Context, fc func() (string, int64), elapsed time.Duration, slow bool) {
+	if ctx != nil {
+		if stmt, ok := ctx.Value(stmtKey{}).(*gorm.Statement); ok && stmt.DB != nil && stmt.SQL.Len() > 0 {
+			l.stats.add(stmt.SQL.String(), stmt.RowsAffected, elapsed, slow)
+			return
+		}
+	}
+	sql, rows := fc()
+	l.stats.add(sql, rows, elapsed, slow)
+}
+
+// Stats returns the statistics of the queries, per fingerprint, the slowest
+// first by 95th percentile latency. It returns nil unless Stats is set.
+func (l *Logger) Stats() []QueryStats {
+	if l.stats == nil {
+		return nil
+	}
+	return l.stats.list()
+}
+
+// ResetStats clears the statistics of the queries.
+func (l *Logger) ResetStats() {
+	if l.stats != nil {
+		l.stats.reset()
+	}
+}
+
+// Close stops logging the periodic summary of the statistics. It should be
+// called once the gorm DB is no longer used, though the summary also stops
+// once the logger is garbage collected.
+func (l *Logger) Close() error {
+	if l.stats != nil {
+		l.stats.once.Do(func() { close(l.stats.done) })
+	}
+	return nil
+}
+
+// summarize logs the summary of the statistics every StatsInterval, until
+// Close is called. It runs on a copy of the logger, which can then be garbage
+// collected.
+func (l Logger) summarize() {
+	ticker := time.NewTicker(l.StatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stats.done:
+			return
+		case <-ticker.C:
+			l.logSummary()
+		}
+	}
+}
+
+// logSummary logs the statistics of the StatsTop slowest fingerprints.
+func (l Logger) logSummary() {
+	for i, q := range l.Stats() {
+		if i >= l.StatsTop {
+			break
+		}
+		l.logger.Info(log.MesgGormStats, log.Map{
+			"fingerprint": q.Fingerprint,
+			"query":       q.Query,
+			"count":       q.Count,
+			"slow":        q.Slow,
+			"p50":         getLatency(q.P50),
+			"p95":         getLatency(q.P95),
+			"max":         getLatency(q.Max),
+			"rows":        q.Rows,
+		})
+	}
+}
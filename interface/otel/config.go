@@ -0,0 +1,86 @@
+package otel
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/roninzo/log"
+)
+
+// Config defines the config for the OTLP logger.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP logs receiver of the collector.
+	//
+	// Optional. Default: "http://localhost:4318/v1/logs"
+	Endpoint string
+
+	// Header are extra http request headers, i.e. for authentication.
+	//
+	// Optional. Default: nil
+	Header http.Header
+
+	// ServiceName is the service.name resource attribute of the records.
+	//
+	// Optional. Default: filepath.Base(os.Args[0])
+	ServiceName string
+
+	// Resource are extra resource attributes of the records, i.e.
+	// log.Map{"service.version": "1.0.0"}.
+	//
+	// Optional. Default: nil
+	Resource log.Map
+
+	// BatchSize is the number of pending records which triggers an export,
+	// in the background.
+	//
+	// Optional. Default: 512
+	BatchSize int
+
+	// FlushInterval is the maximum time a record waits before being
+	// exported.
+	//
+	// Optional. Default: 1 * time.Second
+	FlushInterval time.Duration
+
+	// Client is the http client used to export records.
+	//
+	// Optional. Default: &http.Client{Timeout: 10 * time.Second}
+	Client *http.Client
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Endpoint:      "http://localhost:4318/v1/logs",
+	Header:        nil,
+	ServiceName:   "",
+	Resource:      nil,
+	BatchSize:     512,
+	FlushInterval: 1 * time.Second,
+	Client:        &http.Client{Timeout: 10 * time.Second},
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = ConfigDefault.Endpoint
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = ConfigDefault.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = ConfigDefault.FlushInterval
+	}
+	if cfg.Client == nil {
+		cfg.Client = ConfigDefault.Client
+	}
+	return cfg
+}
@@ -0,0 +1,449 @@
+package otel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// scopeName is the instrumentation scope of records logged without prefix.
+const scopeName = "github.com/roninzo/log"
+
+// Logger is a log.Logger implementation exporting entries as OTLP log records
+// over OTLP/HTTP, using the JSON protobuf encoding. The trace_id and span_id
+// fields, as added by WithContext, become the trace context of the records
+// and the logger prefix becomes their instrumentation scope.
+type Logger struct {
+	exporter *exporter
+	level    levels.Type
+	prefix   string
+}
+
+// New creates an OTLP logger exporting records to the configured endpoint.
+// Records are exported in batches, in the background.
+func New(config ...Config) *Logger {
+	cfg := configDefault(config...)
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = filepath.Base(os.Args[0])
+	}
+	return &Logger{
+		exporter: newExporter(cfg),
+		level:    levels.Info,
+	}
+}
+
+// Flush exports the pending records.
+func (l *Logger) Flush() error {
+	return l.exporter.flush()
+}
+
+// Stats returns the export metrics of the logger, shared with all named
+// loggers.
+func (l *Logger) Stats() Stats {
+	return l.exporter.stats()
+}
+
+// Close exports the pending records, and stops exporting in the background.
+// Note, the exporter is shared with all named loggers.
+func (l *Logger) Close() error {
+	return l.exporter.close()
+}
+
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{
+		exporter: l.exporter,
+		level:    l.level,
+		prefix:   log.Prefixed(l.prefix, name),
+	}
+}
+
+func (l *Logger) Options(funcs ...func(*Logger) *Logger) *Logger {
+	for _, f := range funcs {
+		f(l)
+	}
+	return l
+}
+
+func (l *Logger) WithLevel(level levels.Type) *Logger {
+	l.Level(level)
+	return l
+}
+
+func (l *Logger) WithLevelFromDebug(debug bool) *Logger {
+	switch debug {
+	case true:
+		l.Level(levels.Debug)
+	default:
+		l.Level(levels.Info)
+	}
+	return l
+}
+
+func (l *Logger) Prefix(prefix ...string) string {
+	if len(prefix) > 0 {
+		l.prefix = prefix[0]
+	}
+	return l.prefix
+}
+
+func (l *Logger) Level(level ...levels.Type) levels.Type {
+	if len(level) > 0 {
+		l.level = level[0]
+	}
+	return l.level
+}
+
+func (l Logger) Trace(msg ...interface{}) { l.log(levels.Trace, msg...) }
+func (l Logger) Debug(msg ...interface{}) { l.log(levels.Debug, msg...) }
+func (l Logger) Info(msg ...interface{})  { l.log(levels.Info, msg...) }
+func (l Logger) Warn(msg ...interface{})  { l.log(levels.Warn, msg...) }
+func (l Logger) Error(msg ...interface{}) { l.log(levels.Error, msg...) }
+func (l Logger) Panic(msg ...interface{}) { l.log(levels.Panic, msg...) }
+func (l Logger) Fatal(msg ...interface{}) { l.log(levels.Fatal, msg...) }
+
+func (l Logger) Tracef(template string, args ...interface{}) { l.logf(levels.Trace, template, args...) }
+func (l Logger) Debugf(template string, args ...interface{}) { l.logf(levels.Debug, template, args...) }
+func (l Logger) Infof(template string, args ...interface{})  { l.logf(levels.Info, template, args...) }
+func (l Logger) Warnf(template string, args ...interface{})  { l.logf(levels.Warn, template, args...) }
+func (l Logger) Errorf(template string, args ...interface{}) { l.logf(levels.Error, template, args...) }
+func (l Logger) Panicf(template string, args ...interface{}) { l.logf(levels.Panic, template, args...) }
+func (l Logger) Fatalf(template string, args ...interface{}) { l.logf(levels.Fatal, template, args...) }
+
+func (l Logger) log(level levels.Type, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprint(args...), fields)
+}
+
+func (l Logger) logf(level levels.Type, template string, args ...interface{}) {
+	if level < l.level { // Trace(0) < Info(2) => no logging
+		return
+	}
+	var fields log.Map
+	args, fields = log.ParseArgs(args...)
+	l.output(level, fmt.Sprintf(template, args...), fields)
+}
+
+func (l Logger) output(level levels.Type, msg string, fields log.Map) {
+	scope := l.prefix
+	if scope == "" {
+		scope = scopeName
+	}
+	l.exporter.add(scope, newRecord(time.Now(), level, msg, fields))
+	switch level {
+	case levels.Panic:
+		_ = l.exporter.flush()
+		panic(msg)
+	case levels.Fatal:
+		_ = l.exporter.close()
+		os.Exit(1)
+	}
+}
+
+// retryDelay is the delay before a failed export is retried.
+var retryDelay = 100 * time.Millisecond
+
+// Stats are the export metrics of the logger.
+type Stats struct {
+	Exported uint64 // Records exported successfully.
+	Failed   uint64 // Records that could not be exported, after a retry.
+	Retries  uint64 // Exports that were retried.
+}
+
+// exporter batches records, and exports them to the collector. It is shared
+// by all the loggers derived from one another.
+type exporter struct {
+	Config
+
+	mu       sync.Mutex
+	pending  []scoped
+	counts   Stats
+	full     chan struct{} // signals run that a batch is full
+	done     chan struct{}
+	closed   bool
+	exportMu sync.Mutex // serialises the exports, to keep the records in order
+}
+
+// scoped is a record along with its instrumentation scope.
+type scoped struct {
+	scope  string
+	record record
+}
+
+func newExporter(cfg Config) *exporter {
+	e := &exporter{
+		Config: cfg,
+		full:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *exporter) run() {
+	ticker := time.NewTicker(e.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.full:
+		case <-e.done:
+			return
+		}
+		if err := e.flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "otel: %v\n", err)
+		}
+	}
+}
+
+func (e *exporter) add(scope string, r record) {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.pending = append(e.pending, scoped{scope, r})
+	full := len(e.pending) >= e.BatchSize
+	e.mu.Unlock()
+	if full { // exported by run, not to block the caller
+		select {
+		case e.full <- struct{}{}:
+		default: // already signaled
+		}
+	}
+}
+
+// flush exports the pending records, retrying once on failure.
+func (e *exporter) flush() error {
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	err := e.export(batch)
+	retried := err != nil
+	if retried {
+		time.Sleep(retryDelay)
+		err = e.export(batch)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if retried {
+		e.counts.Retries++
+	}
+	if err != nil {
+		e.counts.Failed += uint64(len(batch))
+	} else {
+		e.counts.Exported += uint64(len(batch))
+	}
+	return err
+}
+
+func (e *exporter) stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.counts
+}
+
+func (e *exporter) close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.done)
+	e.mu.Unlock()
+	return e.flush()
+}
+
+// export posts a batch of records, grouped by instrumentation scope.
+func (e *exporter) export(batch []scoped) error {
+	var (
+		scopes  []string
+		records = map[string][]record{}
+	)
+	for _, s := range batch {
+		if _, ok := records[s.scope]; !ok {
+			scopes = append(scopes, s.scope)
+		}
+		records[s.scope] = append(records[s.scope], s.record)
+	}
+	req := exportRequest{}
+	rl := resourceLogs{
+		Resource: resource{
+			Attributes: attributes(log.Merge(e.Resource, log.Map{"service.name": e.ServiceName})),
+		},
+	}
+	for _, scope := range scopes {
+		rl.ScopeLogs = append(rl.ScopeLogs, scopeLogs{
+			Scope:      instrumentationScope{Name: scope},
+			LogRecords: records[scope],
+		})
+	}
+	req.ResourceLogs = append(req.ResourceLogs, rl)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("could not encode %d records: %w", len(batch), err)
+	}
+	hreq, err := http.NewRequest(http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, vals := range e.Header {
+		hreq.Header[key] = vals
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	resp, err := e.Client.Do(hreq)
+	if err != nil {
+		return fmt.Errorf("could not export %d records: %w", len(batch), err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("could not export %d records: unexpected http status: %s", len(batch), resp.Status)
+	}
+	return nil
+}
+
+// OTLP/HTTP JSON payload, see opentelemetry/proto/collector/logs/v1.
+type (
+	exportRequest struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}
+	resourceLogs struct {
+		Resource  resource    `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+	resource struct {
+		Attributes []keyValue `json:"attributes,omitempty"`
+	}
+	scopeLogs struct {
+		Scope      instrumentationScope `json:"scope"`
+		LogRecords []record             `json:"logRecords"`
+	}
+	instrumentationScope struct {
+		Name string `json:"name"`
+	}
+	record struct {
+		TimeUnixNano         string     `json:"timeUnixNano"`
+		ObservedTimeUnixNano string     `json:"observedTimeUnixNano"`
+		SeverityNumber       int        `json:"severityNumber"`
+		SeverityText         string     `json:"severityText"`
+		Body                 anyValue   `json:"body"`
+		Attributes           []keyValue `json:"attributes,omitempty"`
+		TraceID              string     `json:"traceId,omitempty"`
+		SpanID               string     `json:"spanId,omitempty"`
+	}
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	anyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func newRecord(t time.Time, level levels.Type, msg string, fields log.Map) record {
+	ts := strconv.FormatInt(t.UnixNano(), 10)
+	r := record{
+		TimeUnixNano:         ts,
+		ObservedTimeUnixNano: ts,
+		SeverityNumber:       severity(level),
+		SeverityText:         level.String(),
+		Body:                 value(msg),
+	}
+	attrs := make(log.Map, len(fields))
+	for key, val := range fields {
+		switch key {
+		case TraceIDKey:
+			r.TraceID = fmt.Sprint(val)
+		case SpanIDKey:
+			r.SpanID = fmt.Sprint(val)
+		default:
+			attrs[key] = val
+		}
+	}
+	r.Attributes = attributes(attrs)
+	return r
+}
+
+func attributes(fields log.Map) []keyValue {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	kvs := make([]keyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, keyValue{Key: key, Value: value(fields[key])})
+	}
+	return kvs
+}
+
+// value returns the OTLP AnyValue of a field value.
+func value(v interface{}) anyValue {
+	switch val := v.(type) {
+	case bool:
+		return anyValue{BoolValue: &val}
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		s := fmt.Sprint(val)
+		return anyValue{IntValue: &s}
+	case float32:
+		f := float64(val)
+		return anyValue{DoubleValue: &f}
+	case float64:
+		return anyValue{DoubleValue: &val}
+	case error:
+		s := val.Error()
+		return anyValue{StringValue: &s}
+	default:
+		s := fmt.Sprint(val)
+		return anyValue{StringValue: &s}
+	}
+}
+
+// severity maps a levels.Type to an OTLP SeverityNumber.
+func severity(level levels.Type) int {
+	switch level {
+	case levels.Trace:
+		return 1 // TRACE
+	case levels.Debug:
+		return 5 // DEBUG
+	case levels.Info:
+		return 9 // INFO
+	case levels.Warn:
+		return 13 // WARN
+	case levels.Error:
+		return 17 // ERROR
+	case levels.Panic:
+		return 21 // FATAL
+	case levels.Fatal:
+		return 24 // FATAL4
+	default:
+		return 0 // UNSPECIFIED
+	}
+}
@@ -0,0 +1,49 @@
+// Package otel bridges the log package with OpenTelemetry. It correlates log
+// entries with traces, adding the trace_id and span_id fields of the span
+// active in a context.Context:
+//
+//	func handler(ctx context.Context) {
+//	    lgr := otel.WithContext(ctx, log.Current)
+//	    lgr.Info("Hello") // => trace_id=... span_id=...
+//	}
+//
+// It also provides a log.Logger implementation exporting entries as OTLP log
+// records to an OpenTelemetry collector, see New.
+package otel
+
+import (
+	"context"
+
+	"github.com/roninzo/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names of the trace correlation fields.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// Fields returns the trace correlation fields of the span active in ctx, or
+// an empty map if there is none.
+func Fields(ctx context.Context) log.Map {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return log.Map{}
+	}
+	return log.Map{
+		TraceIDKey: sc.TraceID().String(),
+		SpanIDKey:  sc.SpanID().String(),
+	}
+}
+
+// WithContext returns a Logger adding the trace correlation fields of the
+// span active in ctx to every entry. If there is no active span, lgr is
+// returned as is.
+func WithContext(ctx context.Context, lgr log.Logger) log.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return lgr
+	}
+	return log.With(lgr, fields)
+}
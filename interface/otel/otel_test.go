@@ -0,0 +1,165 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+
+	stdlog "log"
+)
+
+var (
+	traceID, _ = trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _  = trace.SpanIDFromHex("00f067aa0ba902b7")
+)
+
+func spanContext() context.Context {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestWithContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+
+	assert.Equal(t, log.Map{}, Fields(context.Background()))
+	assert.Equal(t, lgr, WithContext(context.Background(), lgr))
+
+	WithContext(spanContext(), lgr).Info("foo")
+	assert.Contains(t, buf.String(), `[trace_id=4bf92f3577b34da6a3ce929d0e0e4736]`)
+	assert.Contains(t, buf.String(), `[span_id=00f067aa0ba902b7]`)
+}
+
+// receiver is an in-process OTLP/HTTP logs receiver.
+type receiver struct {
+	mu       sync.Mutex
+	requests []exportRequest
+	header   http.Header
+	failures int // number of requests to fail
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body exportRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.requests = append(r.requests, body)
+	r.header = req.Header
+}
+
+func TestLogger(t *testing.T) {
+
+	// Test the logger meets the interface
+	var _ log.Logger = new(Logger)
+
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	lgr := New(Config{
+		Endpoint:    srv.URL + "/v1/logs",
+		Header:      http.Header{"Authorization": []string{"secret"}},
+		ServiceName: "myservice",
+		Resource:    log.Map{"service.version": "1.0.0"},
+	})
+	defer lgr.Close()
+
+	// Make sure levels are working
+	lgr.Debug("test debug")
+	lgr.Level(levels.Trace)
+
+	WithContext(spanContext(), lgr).Warn("foo bar", log.Map{"baz": "qux", "n": 42, "ok": true, "f": 1.5})
+	lgr.Named("sub").Tracef("Hello %s", "World")
+	assert.NoError(t, lgr.Flush())
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	assert.Equal(t, "secret", rcv.header.Get("Authorization"))
+	assert.Equal(t, "application/json", rcv.header.Get("Content-Type"))
+	assert.Len(t, rcv.requests, 1)
+	rl := rcv.requests[0].ResourceLogs[0]
+	assert.Equal(t, []keyValue{
+		{Key: "service.name", Value: value("myservice")},
+		{Key: "service.version", Value: value("1.0.0")},
+	}, rl.Resource.Attributes)
+
+	assert.Len(t, rl.ScopeLogs, 2)
+	assert.Equal(t, scopeName, rl.ScopeLogs[0].Scope.Name)
+	r := rl.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, 13, r.SeverityNumber)
+	assert.Equal(t, "warning", r.SeverityText)
+	assert.Equal(t, "foo bar", *r.Body.StringValue)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", r.SpanID)
+	assert.NotEmpty(t, r.TimeUnixNano)
+	assert.Equal(t, []keyValue{
+		{Key: "baz", Value: value("qux")},
+		{Key: "f", Value: value(1.5)},
+		{Key: "n", Value: value(42)},
+		{Key: "ok", Value: value(true)},
+	}, r.Attributes)
+
+	assert.Equal(t, "sub", rl.ScopeLogs[1].Scope.Name)
+	r = rl.ScopeLogs[1].LogRecords[0]
+	assert.Equal(t, 1, r.SeverityNumber)
+	assert.Equal(t, "Hello World", *r.Body.StringValue)
+	assert.Empty(t, r.TraceID)
+	assert.Equal(t, Stats{Exported: 2}, lgr.Stats())
+}
+
+func TestBatch(t *testing.T) {
+	retryDelay = time.Millisecond
+	rcv := &receiver{failures: 1}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	lgr := New(Config{Endpoint: srv.URL, BatchSize: 2})
+	defer lgr.Close()
+
+	// Full batches are exported in the background, and retried once
+	rcv.mu.Lock() // blocks the export, not the caller
+	lgr.Info("foo")
+	lgr.Info("bar")
+	rcv.mu.Unlock()
+	assert.Eventually(t, func() bool { return lgr.Stats().Exported == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, Stats{Exported: 2, Retries: 1}, lgr.Stats())
+
+	// Failed batches are counted
+	rcv.mu.Lock()
+	rcv.failures = 2
+	rcv.mu.Unlock()
+	lgr.Info("baz")
+	assert.Error(t, lgr.Flush())
+	assert.Equal(t, Stats{Exported: 2, Failed: 1, Retries: 2}, lgr.Stats())
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	assert.Len(t, rcv.requests, 1)
+	records := rcv.requests[0].ResourceLogs[0].ScopeLogs[0].LogRecords
+	assert.Len(t, records, 2)
+	assert.Equal(t, "foo", *records[0].Body.StringValue)
+	assert.Equal(t, "bar", *records[1].Body.StringValue)
+}
@@ -0,0 +1,278 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/roninzo/log"
+)
+
+// Make sure the wrappers meet the optional interfaces of database/sql, which
+// fall back on the underlying ones, or on the behaviour of database/sql.
+var (
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.StmtExecContext    = (*stmt)(nil)
+	_ driver.StmtQueryContext   = (*stmt)(nil)
+	_ driver.NamedValueChecker  = (*stmt)(nil)
+	_ driver.ColumnConverter    = (*stmt)(nil)
+)
+
+// errNamed is returned by the drivers which do not support named arguments.
+var errNamed = errors.New("sql: driver does not support the use of Named Parameters")
+
+// conn is a connection logging its statements and transactions.
+type conn struct {
+	driver.Conn
+	driver *Driver
+}
+
+// Prepare implements driver.Conn.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if cp, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = cp.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.driver.logger.Error(ctx, "%s %s: %s", log.MesgSQLPrepare, err, query)
+		return nil, err
+	}
+	c.driver.logger.Info(ctx, "%s %s", log.MesgSQLPrepare, query)
+	return &stmt{Stmt: s, query: query, driver: c.driver}, nil
+}
+
+// Begin implements driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var t driver.Tx
+	var err error
+	if cb, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = cb.BeginTx(ctx, opts)
+	} else if opts.Isolation != 0 || opts.ReadOnly { // as in database/sql
+		err = errors.New("sql: driver does not support non-default isolation level or read-only transactions")
+	} else {
+		t, err = c.Conn.Begin() // deprecated, as a fallback
+	}
+	c.driver.trace(ctx, start, sqlBegin, nil, -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, ctx: ctx, driver: c.driver}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	switch ec := c.Conn.(type) {
+	case driver.ExecerContext:
+		res, err = ec.ExecContext(ctx, query, args)
+	case driver.Execer: // deprecated, as a fallback
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			res, err = ec.Exec(query, vals)
+		}
+	default:
+		return nil, driver.ErrSkip // prepared by database/sql
+	}
+	c.driver.trace(ctx, start, query, args, rowsAffected(res), err)
+	return res, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	switch qc := c.Conn.(type) {
+	case driver.QueryerContext:
+		rows, err = qc.QueryContext(ctx, query, args)
+	case driver.Queryer: // deprecated, as a fallback
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			rows, err = qc.Query(query, vals)
+		}
+	default:
+		return nil, driver.ErrSkip // prepared by database/sql
+	}
+	c.driver.trace(ctx, start, query, args, -1, err)
+	return rows, err
+}
+
+// Ping implements driver.Pinger.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip // default conversion of database/sql
+}
+
+// stmt is a prepared statement logging its executions.
+type stmt struct {
+	driver.Stmt
+	query  string
+	driver *Driver
+}
+
+// Exec implements driver.Stmt.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = se.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			res, err = s.Stmt.Exec(vals) // deprecated, as a fallback
+		}
+	}
+	s.driver.trace(ctx, start, s.query, args, rowsAffected(res), err)
+	return res, err
+}
+
+// Query implements driver.Stmt.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = values(args); err == nil {
+			rows, err = s.Stmt.Query(vals) // deprecated, as a fallback
+		}
+	}
+	s.driver.trace(ctx, start, s.query, args, -1, err)
+	return rows, err
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip // checked by the connection
+}
+
+// ColumnConverter implements driver.ColumnConverter.
+func (s *stmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok { // deprecated, as a fallback
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// tx is a transaction logging its end.
+type tx struct {
+	driver.Tx
+	ctx    context.Context
+	driver *Driver
+}
+
+// Commit implements driver.Tx.
+func (t *tx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.driver.trace(t.ctx, start, sqlCommit, nil, -1, err)
+	return err
+}
+
+// Rollback implements driver.Tx.
+func (t *tx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.driver.trace(t.ctx, start, sqlRollback, nil, -1, err)
+	return err
+}
+
+// namedValues converts ordinal values to named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	ret := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		ret[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return ret
+}
+
+// values converts named values to ordinal values, for the drivers which do
+// not support named arguments.
+func values(args []driver.NamedValue) ([]driver.Value, error) {
+	ret := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamed
+		}
+		ret[i] = arg.Value
+	}
+	return ret, nil
+}
+
+// rowsAffected returns the number of rows affected by an execution, or -1.
+func rowsAffected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return rows
+}
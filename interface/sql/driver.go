@@ -0,0 +1,180 @@
+// Package sql provides a database/sql driver wrapping any other driver, and
+// logging its connections, statements and transactions through any
+// log.Logger, for example:
+//
+//	import(
+//	    "database/sql"
+//	    "github.com/lib/pq"
+//	    "github.com/roninzo/log"
+//	    sqllog "github.com/roninzo/log/interface/sql"
+//	)
+//
+//	func main() {
+//	    sqllog.Register("postgres-log", &pq.Driver{}, log.Current)
+//	    db, err := sql.Open("postgres-log", dsn)
+//	    ...
+//	}
+//
+// Statements and transactions are traced by the gorm adapter, so with its
+// slow threshold, messages and statistics, see logger.Config. Drivers return no
+// record not found errors, which IgnoreRecordNotFoundError does not apply to.
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/interface/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Statements traced for the transactions.
+const (
+	sqlBegin    = "BEGIN"
+	sqlCommit   = "COMMIT"
+	sqlRollback = "ROLLBACK"
+)
+
+// numeric matches the numbered placeholders of the bind variables, i.e. "$1"
+// (PostgreSQL) and "@p1" (SQL Server).
+var numeric = regexp.MustCompile(`(?:\$|@p)(\d+)`)
+
+// Make sure the wrappers meet the interfaces of database/sql.
+var (
+	_ driver.Driver        = (*Driver)(nil)
+	_ driver.DriverContext = (*Driver)(nil)
+	_ driver.Connector     = (*connector)(nil)
+)
+
+// Driver is a driver logging the connections, statements and transactions of
+// another driver.
+type Driver struct {
+	driver.Driver                // Underlying driver.
+	logger        *logger.Logger // Gorm adapter tracing the statements.
+}
+
+// Wrap returns a driver wrapping drv, logging through lgr as per config.
+func Wrap(drv driver.Driver, lgr log.Logger, config ...logger.Config) *Driver {
+	return &Driver{Driver: drv, logger: logger.New(lgr, config...)}
+}
+
+// Register registers a driver wrapping drv under name, logging through lgr as
+// per config, see sql.Register.
+func Register(name string, drv driver.Driver, lgr log.Logger, config ...logger.Config) {
+	stdsql.Register(name, Wrap(drv, lgr, config...))
+}
+
+// WrapConnector returns a connector wrapping c, logging through lgr as per
+// config, for sql.OpenDB.
+func WrapConnector(c driver.Connector, lgr log.Logger, config ...logger.Config) driver.Connector {
+	return &connector{Connector: c, driver: Wrap(c.Driver(), lgr, config...)}
+}
+
+// Logger returns the gorm adapter tracing the statements, i.e. for its
+// statistics.
+func (d *Driver) Logger() *logger.Logger {
+	return d.logger
+}
+
+// Open implements driver.Driver.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	start := time.Now()
+	c, err := d.Driver.Open(name)
+	d.connected(context.Background(), start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, driver: d}, nil
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{Connector: c, driver: d}, nil
+	}
+	return &connector{Connector: dsnConnector{name: name, driver: d.Driver}, driver: d}, nil
+}
+
+// connected logs a connection.
+func (d *Driver) connected(ctx context.Context, start time.Time, err error) {
+	if err != nil {
+		d.logger.Error(ctx, "%s %s", log.MesgSQLConnect, err)
+		return
+	}
+	d.logger.Info(ctx, "%s %s", log.MesgSQLConnect, time.Since(start))
+}
+
+// trace traces a statement, with its bind variables interpolated unless
+// ParameterizedQueries is set.
+func (d *Driver) trace(ctx context.Context, start time.Time, query string, args []driver.NamedValue, rows int64, err error) {
+	if errors.Is(err, driver.ErrSkip) { // traced by the fallback
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	vars := make([]interface{}, len(args))
+	for i, arg := range args {
+		vars[i] = arg.Value
+	}
+	if d.logger.ParameterizedQueries {
+		ctx = logger.ContextWithQuery(ctx, query, vars)
+	}
+	d.logger.Trace(ctx, start, func() (string, int64) {
+		if d.logger.ParameterizedQueries || len(vars) == 0 {
+			return query, rows
+		}
+		var placeholder *regexp.Regexp
+		if numeric.MatchString(query) {
+			placeholder = numeric
+		}
+		return gormlogger.ExplainSQL(query, placeholder, `'`, vars...), rows
+	}, err)
+}
+
+// connector is a connector logging the connections, statements and
+// transactions of another connector.
+type connector struct {
+	driver.Connector
+	driver *Driver
+}
+
+// Connect implements driver.Connector.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := time.Now()
+	cn, err := c.Connector.Connect(ctx)
+	c.driver.connected(ctx, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, driver: c.driver}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is the connector of the drivers which do not implement
+// driver.DriverContext, as in database/sql.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
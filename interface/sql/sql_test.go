@@ -0,0 +1,215 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	stdlog "log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/interface/gorm/logger"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// fakeDriver is an in-memory driver, executing statements as follows:
+// "SLOW" ones sleep, "FAIL" ones fail, and the others affect one row.
+type fakeDriver struct {
+	context bool // implement the context interfaces
+}
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) {
+	if name == "FAIL" {
+		return nil, errors.New("connection refused")
+	}
+	if d.context {
+		return &fakeContextConn{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+// fakeConn is a connection of fakeDriver, without the optional interfaces.
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "SYNTAX") {
+		return nil, errors.New("syntax error")
+	}
+	return &fakeStmt{query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+// fakeContextConn is a connection of fakeDriver, with the optional
+// interfaces.
+type fakeContextConn struct{ fakeConn }
+
+func (c *fakeContextConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return (&fakeStmt{query: query}).Exec(nil)
+}
+func (c *fakeContextConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return (&fakeStmt{query: query}).Query(nil)
+}
+
+// fakeStmt is a statement of fakeConn.
+type fakeStmt struct{ query string }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.run(); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+func (s *fakeStmt) run() error {
+	switch {
+	case strings.Contains(s.query, "SLOW"):
+		time.Sleep(20 * time.Millisecond)
+	case strings.Contains(s.query, "FAIL"):
+		return errors.New("query failed")
+	}
+	return nil
+}
+
+// fakeRows are the rows of fakeStmt, one row of one column.
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string { return []string{"n"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// fakeTx is a transaction of fakeConn.
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return errors.New("rollback failed") }
+
+func open(t *testing.T, drv driver.Driver, config ...logger.Config) (*stdsql.DB, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	db := stdsql.OpenDB(WrapConnector(&dsnConnector{name: "fake", driver: drv}, lgr, config...))
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, buf
+}
+
+func TestDriver(t *testing.T) {
+	for _, drv := range []fakeDriver{{context: false}, {context: true}} {
+		db, buf := open(t, drv, logger.Config{SlowThreshold: 10 * time.Millisecond})
+
+		// Test the connection and the statements
+		_, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "john", 1)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "[INFO]  "+log.MesgSQLConnect+" ")
+		assert.Contains(t, buf.String(), "[INFO]  "+log.MesgGormTrace+" ")
+		assert.Contains(t, buf.String(), "[query=UPDATE users SET name = 'john' WHERE id = 1]")
+		assert.Contains(t, buf.String(), "[rows=1]")
+		assert.Contains(t, buf.String(), "[fingerprint="+logger.Fingerprint("UPDATE users SET name = ? WHERE id = ?")+"]")
+		assert.Regexp(t, `\[source=\S+/sql_test\.go:\d+\]`, buf.String(), "caller of database/sql")
+		assert.Equal(t, drv.context, !strings.Contains(buf.String(), log.MesgSQLPrepare), "prepared by database/sql")
+
+		buf.Reset()
+		var n int
+		assert.NoError(t, db.QueryRow("SELECT n FROM numbers").Scan(&n))
+		assert.Equal(t, 1, n)
+		assert.Contains(t, buf.String(), "[query=SELECT n FROM numbers]")
+		assert.Contains(t, buf.String(), "[rows=-]")
+
+		// Test the slow and failed statements
+		buf.Reset()
+		_, err = db.Exec("SELECT SLOW")
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "[WARN]  "+log.MesgGormTrace+" SLOW SQL >= 10ms")
+		buf.Reset()
+		_, err = db.Exec("SELECT FAIL")
+		assert.EqualError(t, err, "query failed")
+		assert.Contains(t, buf.String(), "[ERROR] "+log.MesgGormTrace+" query failed")
+		buf.Reset()
+		_, err = db.Prepare("SYNTAX")
+		assert.EqualError(t, err, "syntax error")
+		assert.Contains(t, buf.String(), "[ERROR] "+log.MesgSQLPrepare+" syntax error: SYNTAX")
+
+		// Test the transactions
+		buf.Reset()
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+		assert.Contains(t, buf.String(), "[query=BEGIN]")
+		assert.Contains(t, buf.String(), "[query=COMMIT]")
+		buf.Reset()
+		tx, err = db.Begin()
+		assert.NoError(t, err)
+		assert.EqualError(t, tx.Rollback(), "rollback failed")
+		assert.Contains(t, buf.String(), "[ERROR] "+log.MesgGormTrace+" rollback failed")
+		assert.Contains(t, buf.String(), "[query=ROLLBACK]")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	// Test the parameterised queries, the level and the statistics
+	db, buf := open(t, fakeDriver{context: true}, logger.Config{
+		ParameterizedQueries: true,
+		RedactColumns:        map[string]logger.RedactFunc{"email": logger.Redact},
+		LogLevel:             gormlogger.Error,
+		Stats:                true,
+	})
+	_, err := db.Exec("UPDATE users SET email = $1 WHERE id = $2", "john@example.com", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+	_, err = db.Exec("SELECT FAIL WHERE email = $1", "john@example.com")
+	assert.Error(t, err)
+	assert.Contains(t, buf.String(), "[query=SELECT FAIL WHERE email = $1]")
+	assert.Contains(t, buf.String(), "[vars=[[REDACTED]]]")
+	assert.NotContains(t, buf.String(), "john@example.com")
+	stats := db.Driver().(*Driver).Logger().Stats()
+	assert.Len(t, stats, 2, "update and select")
+}
+
+func TestRegister(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	Register("fake-log", fakeDriver{}, lgr)
+	assert.Contains(t, stdsql.Drivers(), "fake-log")
+
+	db, err := stdsql.Open("fake-log", "FAIL")
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.EqualError(t, db.Ping(), "connection refused")
+	assert.Contains(t, buf.String(), "[ERROR] "+log.MesgSQLConnect+" connection refused")
+}
+
+func TestExplain(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(stdlog.New(buf, "", 0))
+	lgr.Level(levels.Info)
+	d := Wrap(fakeDriver{}, lgr)
+	d.trace(context.Background(), time.Now(), "SELECT * FROM users WHERE id = @p1 AND name = @p2", []driver.NamedValue{{Value: 1}, {Value: "john"}}, -1, nil)
+	assert.Contains(t, buf.String(), "[query=SELECT * FROM users WHERE id = 1 AND name = 'john']")
+	buf.Reset()
+	d.trace(context.Background(), time.Now(), "SELECT 1", nil, -1, driver.ErrSkip)
+	assert.Empty(t, buf.String())
+}
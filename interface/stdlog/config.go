@@ -0,0 +1,41 @@
+package stdlog
+
+import "github.com/roninzo/log/levels"
+
+// Config defines the config for Redirect and the writers.
+type Config struct {
+	// LevelFunc returns the level of a line given its message, and the
+	// message without its level marker.
+	//
+	// Optional. Default: DefaultLevel
+	LevelFunc func(msg string) (levels.Type, string)
+
+	// Slog also redirects slog.Default, see NewHandler. It requires go1.21 or
+	// later, and is ignored otherwise.
+	//
+	// Optional. Default: false
+	Slog bool
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	LevelFunc: DefaultLevel,
+	Slog:      false,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.LevelFunc == nil {
+		cfg.LevelFunc = ConfigDefault.LevelFunc
+	}
+	return cfg
+}
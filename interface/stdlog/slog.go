@@ -0,0 +1,112 @@
+//go:build go1.21
+// +build go1.21
+
+package stdlog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// handler is a slog.Handler logging the records to a log.Logger.
+type handler struct {
+	logger log.Logger
+	attrs  log.Map // fields of the attributes of WithAttrs
+	group  string  // prefix of the keys of the groups of WithGroup, i.e. "a.b."
+}
+
+// NewHandler returns a slog.Handler logging the records to lgr, with their
+// attributes as fields, the keys of the groups joined with dots.
+func NewHandler(lgr log.Logger) slog.Handler {
+	return &handler{logger: lgr, attrs: log.Map{}}
+}
+
+// redirectSlog redirects slog.Default to lgr, until the returned function is
+// called.
+func redirectSlog(lgr log.Logger) func() {
+	prev := slog.Default()
+	slog.SetDefault(slog.New(NewHandler(lgr)))
+	return func() { slog.SetDefault(prev) }
+}
+
+// Enabled implements slog.Handler.
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return fromSlog(level) >= h.logger.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(log.Map, len(h.attrs)+r.NumAttrs()+1)
+	for key, val := range h.attrs {
+		fields[key] = val
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		fields[KeySource] = frame.File + ":" + strconv.Itoa(frame.Line)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.group, a)
+		return true
+	})
+	logAt(h.logger, fromSlog(r.Level), r.Message, fields)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := &handler{logger: h.logger, attrs: make(log.Map, len(h.attrs)+len(attrs)), group: h.group}
+	for key, val := range h.attrs {
+		c.attrs[key] = val
+	}
+	for _, a := range attrs {
+		addAttr(c.attrs, h.group, a)
+	}
+	return c
+}
+
+// WithGroup implements slog.Handler.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// addAttr adds an attribute to fields, under the prefix of its groups.
+func addAttr(fields log.Map, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(fields, group, ga)
+		}
+		return
+	}
+	fields[group+a.Key] = a.Value.Any()
+}
+
+// fromSlog returns the level of a slog level.
+func fromSlog(level slog.Level) levels.Type {
+	switch {
+	case level < slog.LevelDebug:
+		return levels.Trace
+	case level < slog.LevelInfo:
+		return levels.Debug
+	case level < slog.LevelWarn:
+		return levels.Info
+	case level < slog.LevelError:
+		return levels.Warn
+	default:
+		return levels.Error
+	}
+}
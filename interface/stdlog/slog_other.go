@@ -0,0 +1,11 @@
+//go:build !go1.21
+// +build !go1.21
+
+package stdlog
+
+import "github.com/roninzo/log"
+
+// redirectSlog is a no-op, slog requiring go1.21 or later.
+func redirectSlog(log.Logger) func() {
+	return func() {}
+}
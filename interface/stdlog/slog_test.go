@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package stdlog
+
+import (
+	"bytes"
+	"context"
+	golog "log"
+	"log/slog"
+	"testing"
+
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(golog.New(buf, "", 0))
+	lgr.Level(levels.Debug)
+	prev := slog.Default()
+	out, flags := golog.Writer(), golog.Flags()
+
+	restore := Redirect(lgr, Config{Slog: true})
+	slog.Warn("careful", "user", "john", slog.Group("req", "id", "rid-1"))
+	assert.Contains(t, buf.String(), "[WARN]  careful")
+	assert.Contains(t, buf.String(), "[user=john]")
+	assert.Contains(t, buf.String(), "[req.id=rid-1]")
+	assert.Contains(t, buf.String(), "[source=")
+	buf.Reset()
+	slog.With("a", "b").WithGroup("g").Debug("details", "c", "d")
+	assert.Contains(t, buf.String(), "[DEBUG] details")
+	assert.Contains(t, buf.String(), "[a=b]")
+	assert.Contains(t, buf.String(), "[g.c=d]")
+	buf.Reset()
+	slog.Log(context.Background(), slog.LevelDebug-4, "hidden")
+	assert.Empty(t, buf.String())
+
+	// Test the standard library's global logger keeps being parsed
+	golog.Print("[ERROR] boom")
+	assert.Contains(t, buf.String(), "[ERROR] boom")
+
+	restore()
+	assert.Equal(t, prev, slog.Default())
+	assert.Equal(t, out, golog.Writer())
+	assert.Equal(t, flags, golog.Flags())
+}
@@ -0,0 +1,191 @@
+// Package stdlog redirects the output of the standard library's global logger,
+// and optionally of slog.Default, to any log.Logger, for libraries logging
+// with them directly, for example:
+//
+//	import(
+//	    "github.com/roninzo/log"
+//	    "github.com/roninzo/log/interface/stdlog"
+//	)
+//
+//	func main() {
+//	    restore := stdlog.Redirect(lgr)
+//	    defer restore()
+//	    ...
+//	}
+//
+// The logger must not write to the standard library's global logger itself,
+// as log.NewStandard does, or its entries would loop.
+package stdlog
+
+import (
+	golog "log"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Field keys of the log entries.
+const (
+	KeyPrefix = "prefix"
+	KeySource = "source"
+)
+
+// source matches the file name and line number of a line, see log.Lshortfile.
+var source = regexp.MustCompile(`^(.+?:\d+): `)
+
+// Redirect redirects the output of the standard library's global logger to
+// lgr, and of slog.Default when Slog is set, until restore is called.
+func Redirect(lgr log.Logger, config ...Config) (restore func()) {
+	cfg := configDefault(config...)
+	out, prefix, flags := golog.Writer(), golog.Prefix(), golog.Flags()
+	restoreSlog := func() {}
+	if cfg.Slog {
+		restoreSlog = redirectSlog(lgr) // resets the flags of the global logger
+	}
+	golog.SetOutput(NewWriter(lgr, prefix, flags, cfg))
+	golog.SetFlags(flags)
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			restoreSlog()
+			golog.SetOutput(out)
+			golog.SetPrefix(prefix)
+			golog.SetFlags(flags)
+		})
+	}
+}
+
+// Writer is an io.Writer logging the lines of a logger of the standard
+// library, with its flags and prefix, to a log.Logger.
+type Writer struct {
+	logger log.Logger
+	prefix string
+	flags  int
+	cfg    Config
+}
+
+// NewWriter creates a new Writer, for a logger of the standard library with
+// prefix and flags, i.e. log.New(NewWriter(lgr, prefix, flags), prefix, flags).
+func NewWriter(lgr log.Logger, prefix string, flags int, config ...Config) *Writer {
+	return &Writer{
+		logger: lgr,
+		prefix: prefix,
+		flags:  flags,
+		cfg:    configDefault(config...),
+	}
+}
+
+// Write is the write method from the io.Writer interface in the standard lib
+func (w *Writer) Write(p []byte) (n int, err error) {
+	msg, fields := w.parse(strings.TrimSuffix(string(p), "\n"))
+	level, msg := w.cfg.LevelFunc(msg)
+	logAt(w.logger, level, msg, fields)
+	return len(p), nil
+}
+
+// parse returns the message of a line, without the header written as per
+// the flags and prefix, and the fields of the header. The date and time are
+// left out, for the logger to add its own.
+func (w *Writer) parse(line string) (string, log.Map) {
+	fields := log.Map{}
+	if name := strings.TrimRight(w.prefix, ": "); name != "" {
+		fields[KeyPrefix] = name
+	}
+	if w.flags&golog.Lmsgprefix == 0 {
+		line = strings.TrimPrefix(line, w.prefix)
+	}
+	if w.flags&golog.Ldate != 0 && len(line) >= len("2006/01/02 ") {
+		line = line[len("2006/01/02 "):]
+	}
+	if w.flags&(golog.Ltime|golog.Lmicroseconds) != 0 {
+		n := len("15:04:05 ")
+		if w.flags&golog.Lmicroseconds != 0 {
+			n += len(".000000")
+		}
+		if len(line) >= n {
+			line = line[n:]
+		}
+	}
+	if w.flags&(golog.Lshortfile|golog.Llongfile) != 0 {
+		if m := source.FindStringSubmatch(line); m != nil {
+			fields[KeySource] = m[1]
+			line = line[len(m[0]):]
+		}
+	}
+	if w.flags&golog.Lmsgprefix != 0 {
+		line = strings.TrimPrefix(line, w.prefix)
+	}
+	return line, fields
+}
+
+// GuessLevel guesses the level of a message from its marker, i.e. "[ERROR]",
+// "WARN:" or "DEBUG", and returns the message without its marker. Markers
+// without brackets nor colon must be uppercase.
+func GuessLevel(msg string) (levels.Type, string, bool) {
+	s := strings.TrimLeft(msg, " ")
+	var word, rest string
+	if strings.HasPrefix(s, "[") {
+		i := strings.IndexByte(s, ']')
+		if i < 0 {
+			return levels.Info, msg, false
+		}
+		word, rest = s[1:i], strings.TrimPrefix(s[i+1:], ":")
+	} else {
+		i := strings.IndexAny(s, ": ")
+		if i <= 0 {
+			return levels.Info, msg, false
+		}
+		word, rest = s[:i], s[i:]
+		if rest[0] == ':' {
+			rest = rest[1:]
+		} else if word != strings.ToUpper(word) {
+			return levels.Info, msg, false
+		}
+	}
+	if rest != "" && rest[0] != ' ' {
+		return levels.Info, msg, false
+	}
+	level, ok := levels.FromName(word) // i.e. "[WRN]" or "ERRO:"
+	if !ok {
+		return levels.Info, msg, false
+	}
+	return level, strings.TrimLeft(rest, " "), true
+}
+
+// DefaultLevel returns the level guessed from the marker of a message, see
+// GuessLevel, or else Info. Panic and Fatal levels are lowered to Error, the
+// standard library panicking or exiting itself.
+func DefaultLevel(msg string) (levels.Type, string) {
+	level, msg, _ := GuessLevel(msg)
+	if level > levels.Error {
+		level = levels.Error
+	}
+	return level, msg
+}
+
+// logAt logs a message at a level.
+func logAt(lgr log.Logger, level levels.Type, msg string, fields log.Map) {
+	args := []interface{}{msg}
+	if len(fields) > 0 {
+		args = append(args, fields)
+	}
+	switch level {
+	case levels.Trace:
+		lgr.Trace(args...)
+	case levels.Debug:
+		lgr.Debug(args...)
+	case levels.Info:
+		lgr.Info(args...)
+	case levels.Warn:
+		lgr.Warn(args...)
+	case levels.Error:
+		lgr.Error(args...)
+	case levels.Panic:
+		lgr.Panic(args...)
+	case levels.Fatal:
+		lgr.Fatal(args...)
+	}
+}
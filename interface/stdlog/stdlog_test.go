@@ -0,0 +1,102 @@
+package stdlog
+
+import (
+	"bytes"
+	golog "log"
+	"os"
+	"testing"
+
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirect(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := std.New(golog.New(buf, "", 0))
+	lgr.Level(levels.Trace)
+	golog.SetPrefix("app: ")
+	golog.SetFlags(golog.LstdFlags | golog.Lshortfile)
+	defer golog.SetPrefix("")
+	defer golog.SetFlags(golog.LstdFlags)
+
+	restore := Redirect(lgr)
+	golog.Printf("[ERROR] %s", "boom")
+	assert.Contains(t, buf.String(), "[ERROR] boom")
+	assert.Contains(t, buf.String(), "[prefix=app]")
+	assert.Contains(t, buf.String(), "[source=stdlog_test.go:")
+	assert.NotContains(t, buf.String(), "app: ")
+	buf.Reset()
+	golog.Print("WARN: careful\n")
+	assert.Contains(t, buf.String(), "[WARN]  careful ")
+	buf.Reset()
+	golog.Print("hello")
+	assert.Contains(t, buf.String(), "[INFO]  hello ")
+
+	// Test the redirection is reversible
+	restore()
+	restore()
+	assert.Equal(t, os.Stderr, golog.Writer())
+	assert.Equal(t, "app: ", golog.Prefix())
+	assert.Equal(t, golog.LstdFlags|golog.Lshortfile, golog.Flags())
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		prefix string
+		flags  int
+		line   string
+		msg    string
+		source string
+	}{
+		{"", 0, "msg", "msg", ""},
+		{"", golog.LstdFlags, "2009/01/23 01:23:23 msg", "msg", ""},
+		{"", golog.Ltime | golog.Lmicroseconds, "01:23:23.123123 msg", "msg", ""},
+		{"", golog.Ldate | golog.Llongfile, "2009/01/23 /a/b/c/d.go:23: msg: with colon", "msg: with colon", "/a/b/c/d.go:23"},
+		{"app: ", golog.LstdFlags | golog.Lshortfile, "app: 2009/01/23 01:23:23 d.go:23: msg", "msg", "d.go:23"},
+		{"app: ", golog.LstdFlags | golog.Lmsgprefix, "2009/01/23 01:23:23 app: msg", "msg", ""},
+	}
+	for _, tt := range tests {
+		msg, fields := NewWriter(nil, tt.prefix, tt.flags).parse(tt.line)
+		assert.Equal(t, tt.msg, msg, tt.line)
+		if tt.source != "" {
+			assert.Equal(t, tt.source, fields[KeySource], tt.line)
+		} else {
+			assert.NotContains(t, fields, KeySource, tt.line)
+		}
+		if tt.prefix != "" {
+			assert.Equal(t, "app", fields[KeyPrefix], tt.line)
+		}
+	}
+}
+
+func TestGuessLevel(t *testing.T) {
+	tests := []struct {
+		msg   string
+		level levels.Type
+		rest  string
+		ok    bool
+	}{
+		{"[ERROR] boom", levels.Error, "boom", true},
+		{"[warn]: careful", levels.Warn, "careful", true},
+		{"WARN: careful", levels.Warn, "careful", true},
+		{"debug: details", levels.Debug, "details", true},
+		{"DEBUG details", levels.Debug, "details", true},
+		{" [DBG] details", levels.Debug, "details", true},
+		{"[FATAL] bye", levels.Fatal, "bye", true},
+		{"Error while reading", levels.Info, "Error while reading", false},
+		{"info", levels.Info, "info", false},
+		{"[api] started", levels.Info, "[api] started", false},
+		{"http://localhost", levels.Info, "http://localhost", false},
+	}
+	for _, tt := range tests {
+		level, rest, ok := GuessLevel(tt.msg)
+		assert.Equal(t, tt.level, level, tt.msg)
+		assert.Equal(t, tt.rest, rest, tt.msg)
+		assert.Equal(t, tt.ok, ok, tt.msg)
+	}
+
+	level, msg := DefaultLevel("[FATAL] bye")
+	assert.Equal(t, levels.Error, level)
+	assert.Equal(t, "bye", msg)
+}
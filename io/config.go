@@ -0,0 +1,73 @@
+package io
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/roninzo/log/levels"
+)
+
+// Config defines the config for the writers.
+type Config struct {
+	// NoPanic logs the Panic and Fatal writes at Error, rather than
+	// panicking or exiting, i.e. for writers given to libraries.
+	//
+	// Optional. Default: false
+	NoPanic bool
+
+	// LevelFunc returns the level of a line when detected, and the line
+	// without its level prefix, i.e. MatchPrefix. Line writers only.
+	//
+	// Optional. Default: nil, the level of the writer
+	LevelFunc func(line string) (levels.Type, string, bool)
+
+	// MaxLineSize is the size from which partial lines are logged without
+	// waiting for their newline. Line writers only.
+	//
+	// Optional. Default: 65536
+	MaxLineSize int
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	NoPanic:     false,
+	LevelFunc:   nil,
+	MaxLineSize: 64 * 1024,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.MaxLineSize <= 0 {
+		cfg.MaxLineSize = ConfigDefault.MaxLineSize
+	}
+	return cfg
+}
+
+// MatchPrefix returns a LevelFunc detecting the level of the lines from their
+// prefix, the longest matching one, i.e.:
+//
+//	MatchPrefix(map[string]levels.Type{"E ": levels.Error, "W ": levels.Warn})
+func MatchPrefix(prefixes map[string]levels.Type) func(line string) (levels.Type, string, bool) {
+	keys := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		keys = append(keys, prefix)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	return func(line string) (levels.Type, string, bool) {
+		for _, prefix := range keys {
+			if strings.HasPrefix(line, prefix) {
+				return prefixes[prefix], strings.TrimLeft(line[len(prefix):], " "), true
+			}
+		}
+		return 0, line, false
+	}
+}
@@ -7,22 +7,41 @@
 //     )
 //
 //     func main() {
-//         w := logio.NewCurrentWriter(levels.Info)
+//         w, err := logio.NewCurrentWriterE(levels.Info)
+//         ...
 //         io.WriteString(w, "foo")
 //     }
 package io
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/roninzo/log"
 	"github.com/roninzo/log/levels"
 )
 
+// ErrInvalidLevel is returned for levels other than levels.Trace to
+// levels.Silent.
+var ErrInvalidLevel = errors.New("invalid logger level")
+
 // CurrentWriter uses the current package level logger for io writing
 type CurrentWriter struct {
-	Level levels.Type
+	Level   levels.Type
+	NoPanic bool // Log Panic and Fatal writes at Error, see Config
+}
+
+// NewCurrentWriter creates a new CurrentWriter, see NewCurrentWriterE. Writes
+// at an invalid level return ErrInvalidLevel.
+//
+// Deprecated: use NewCurrentWriterE, which reports invalid levels.
+func NewCurrentWriter(level levels.Type) *CurrentWriter {
+	return &CurrentWriter{
+		Level: level,
+	}
 }
 
-// NewCurrentWriter creates a new CurrentWriter. The levels that can be passed
+// NewCurrentWriterE creates a new CurrentWriter. The levels that can be passed
 // to it are:
 // - levels.Trace:
 // - levels.Debug:
@@ -31,43 +50,45 @@ type CurrentWriter struct {
 // - levels.Error:
 // - levels.Panic:
 // - levels.Fatal:
-func NewCurrentWriter(level levels.Type) *CurrentWriter {
-	return &CurrentWriter{
-		Level: level,
+// - levels.Silent: discards the writes
+// It returns ErrInvalidLevel for any other level.
+func NewCurrentWriterE(level levels.Type, config ...Config) (*CurrentWriter, error) {
+	if err := validate(level); err != nil {
+		return nil, err
 	}
+	return &CurrentWriter{
+		Level:   level,
+		NoPanic: configDefault(config...).NoPanic,
+	}, nil
 }
 
 // Write is the write method from the io.Writer interface in the standard lib
 func (l CurrentWriter) Write(p []byte) (n int, err error) {
-	switch l.Level {
-	case levels.Trace:
-		log.Trace(string(p))
-	case levels.Debug:
-		log.Debug(string(p))
-	case levels.Info:
-		log.Info(string(p))
-	case levels.Warn:
-		log.Warn(string(p))
-	case levels.Error:
-		log.Error(string(p))
-	case levels.Panic:
-		log.Panic(string(p))
-	case levels.Fatal:
-		log.Fatal(string(p))
-	default:
-		log.Panicf("Invalid logger level selected: %d", l.Level)
+	if err := write(log.Current, l.Level, l.NoPanic, string(p), nil); err != nil {
+		return 0, err
 	}
-
 	return len(p), nil
 }
 
 // Writer uses the configured logger for io writing
 type Writer struct {
-	Logger log.Logger
-	Level  levels.Type
+	Logger  log.Logger
+	Level   levels.Type
+	NoPanic bool // Log Panic and Fatal writes at Error, see Config
+}
+
+// NewWriter creates a new Writer, see NewWriterE. Writes at an invalid level
+// return ErrInvalidLevel.
+//
+// Deprecated: use NewWriterE, which reports invalid levels.
+func NewWriter(lgr log.Logger, level levels.Type) *Writer {
+	return &Writer{
+		Logger: lgr,
+		Level:  level,
+	}
 }
 
-// NewWriter creates a new Writer. It accepts a logger and a level that
+// NewWriterE creates a new Writer. It accepts a logger and a level that
 // will be written on the io.Writer interface. The levels you can pass in are:
 // - levels.Trace:
 // - levels.Debug:
@@ -76,33 +97,63 @@ type Writer struct {
 // - levels.Error:
 // - levels.Panic:
 // - levels.Fatal:
-func NewWriter(lgr log.Logger, level levels.Type) *Writer {
-	return &Writer{
-		Logger: lgr,
-		Level:  level,
+// - levels.Silent: discards the writes
+// It returns ErrInvalidLevel for any other level.
+func NewWriterE(lgr log.Logger, level levels.Type, config ...Config) (*Writer, error) {
+	if err := validate(level); err != nil {
+		return nil, err
 	}
+	return &Writer{
+		Logger:  lgr,
+		Level:   level,
+		NoPanic: configDefault(config...).NoPanic,
+	}, nil
 }
 
 // Write is the write method from the io.Writer interface in the standard lib
 func (l Writer) Write(p []byte) (n int, err error) {
-	switch l.Level {
+	if err := write(l.Logger, l.Level, l.NoPanic, string(p), nil); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// validate returns ErrInvalidLevel for an invalid level.
+func validate(level levels.Type) error {
+	if level < levels.Trace || level > levels.Silent {
+		return fmt.Errorf("%w: %d", ErrInvalidLevel, level)
+	}
+	return nil
+}
+
+// write logs a message with fields at a level, or at Error for Panic and Fatal
+// with noPanic. It discards the message at Silent.
+func write(lgr log.Logger, level levels.Type, noPanic bool, msg string, fields log.Map) error {
+	if noPanic && (level == levels.Panic || level == levels.Fatal) {
+		level = levels.Error
+	}
+	args := []interface{}{msg}
+	if len(fields) > 0 {
+		args = append(args, fields)
+	}
+	switch level {
 	case levels.Trace:
-		l.Logger.Trace(string(p))
+		lgr.Trace(args...)
 	case levels.Debug:
-		l.Logger.Debug(string(p))
+		lgr.Debug(args...)
 	case levels.Info:
-		l.Logger.Info(string(p))
+		lgr.Info(args...)
 	case levels.Warn:
-		l.Logger.Warn(string(p))
+		lgr.Warn(args...)
 	case levels.Error:
-		l.Logger.Error(string(p))
+		lgr.Error(args...)
 	case levels.Panic:
-		l.Logger.Panic(string(p))
+		lgr.Panic(args...)
 	case levels.Fatal:
-		l.Logger.Fatal(string(p))
+		lgr.Fatal(args...)
+	case levels.Silent:
 	default:
-		l.Logger.Panicf("Invalid logger level selected: %d", l.Level)
+		return validate(level)
 	}
-
-	return len(p), nil
+	return nil
 }
@@ -2,9 +2,14 @@ package io
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/roninzo/log"
@@ -57,17 +62,10 @@ func TestCurrentIO(t *testing.T) {
 	buf.Reset()
 
 	// Testing a non-existant level
-	defer func() {
-		if r := recover(); r != nil {
-			t.Log(r)
-		}
-	}()
-	log.Current = def // Test logger does not panic. Using one that does
-	o = NewCurrentWriter(5000)
-	_, _ = io.WriteString(o, "not happening")
-	assert.Contains(t, buf.String(), `level=000 msg="testing"`)
-	buf.Reset()
-	t.Error("Not happening Logger failed to panic")
+	n, err := io.WriteString(NewCurrentWriter(5000), "not happening")
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.Zero(t, n)
+	assert.Empty(t, buf.String())
 }
 
 func TestIO(t *testing.T) {
@@ -110,16 +108,337 @@ func TestIO(t *testing.T) {
 	buf.Reset()
 
 	// Testing a non-existant level
+	n, err := io.WriteString(NewWriter(lgr, 5000), "not happening")
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.Zero(t, n)
+	assert.Empty(t, buf.String())
+}
+
+func TestCurrentIOE(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf)
+	def := log.Current
+	log.Current = lgr
 	defer func() {
-		if r := recover(); r != nil {
-			t.Log(r)
-		}
+		log.Current = def
+	}()
+
+	o, err := NewCurrentWriterE(levels.Trace)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=trace msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Debug)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=debug msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Info)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=info msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Warn)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=warning msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Error)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=error msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Panic)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=panic msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Fatal)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=fatal msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Fatal, Config{NoPanic: true})
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=error msg="testing"`)
+	buf.Reset()
+
+	o, err = NewCurrentWriterE(levels.Silent)
+	assert.NoError(t, err)
+	n, err := io.WriteString(o, "testing")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+	assert.Empty(t, buf.String())
+
+	// Testing a non-existant level
+	o, err = NewCurrentWriterE(5000)
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.Nil(t, o)
+	n, err = io.WriteString(CurrentWriter{Level: 5000}, "not happening")
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.Zero(t, n)
+	assert.Empty(t, buf.String())
+}
+
+func TestIOE(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf)
+
+	o, err := NewWriterE(lgr, levels.Trace)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=trace msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Debug)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=debug msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Info)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=info msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Warn)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=warning msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Error)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=error msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Panic)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=panic msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Fatal)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=fatal msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Panic, Config{NoPanic: true})
+	assert.NoError(t, err)
+	_, _ = io.WriteString(o, "testing")
+	assert.Contains(t, buf.String(), `level=error msg="testing"`)
+	buf.Reset()
+
+	o, err = NewWriterE(lgr, levels.Silent)
+	assert.NoError(t, err)
+	n, err := io.WriteString(o, "testing")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+	assert.Empty(t, buf.String())
+
+	// Testing a non-existant level
+	o, err = NewWriterE(log.Current, 5000)
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.EqualError(t, err, "invalid logger level: 5000")
+	assert.Nil(t, o)
+	o, err = NewWriterE(log.Current, -1)
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	n, err = io.WriteString(Writer{Logger: lgr, Level: 5000}, "not happening")
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	assert.Zero(t, n)
+	assert.Empty(t, buf.String())
+}
+
+func TestLineWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf)
+
+	// Test the lines are buffered, split and stripped
+	w, err := NewLineWriter(lgr, levels.Info)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(w, "hel")
+	assert.Empty(t, buf.String())
+	_, _ = io.WriteString(w, "lo\r\nwor")
+	assert.Equal(t, `level=info msg="hello"`, buf.String())
+	buf.Reset()
+	_, _ = io.WriteString(w, "ld\n\nfoo\nbar")
+	assert.Equal(t, `level=info msg="world"level=info msg="foo"`, buf.String())
+	buf.Reset()
+	assert.NoError(t, w.Close())
+	assert.Equal(t, `level=info msg="bar"`, buf.String())
+	buf.Reset()
+	assert.NoError(t, w.Close())
+	assert.Empty(t, buf.String())
+
+	// Test the level detection and the maximum line size
+	w, err = NewLineWriter(lgr, levels.Info, Config{
+		LevelFunc:   MatchPrefix(map[string]levels.Type{"E": levels.Error, "ERR:": levels.Warn}),
+		MaxLineSize: 8,
+	})
+	_, _ = io.WriteString(w, "ERR: boom\nE oops\nall good\nlonger than eight")
+	assert.Equal(t, `level=warning msg="boom"level=error msg="oops"level=info msg="all good"level=info msg="longer t"level=info msg="han eigh"`, buf.String())
+	buf.Reset()
+
+	// Test the current package level logger
+	def := log.Current
+	log.Current = lgr
+	defer func() {
+		log.Current = def
 	}()
-	o = NewWriter(log.Current, 5000) // Test logger does not panic. Using one that does
-	_, _ = io.WriteString(o, "not happening")
-	assert.Contains(t, buf.String(), `level=000 msg="testing"`)
+	w, err = NewCurrentLineWriter(levels.Warn)
+	assert.NoError(t, err)
+	_, _ = io.WriteString(w, "testing\n")
+	assert.Equal(t, `level=warning msg="testing"`, buf.String())
+	buf.Reset()
+
+	// Test concurrent writes keep whole lines
+	w, err = NewLineWriter(lgr, levels.Info)
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = io.WriteString(w, "line\n")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, strings.Count(buf.String(), `msg="line"`))
+
+	// Test the invalid, silent and panic levels
+	_, err = NewLineWriter(lgr, 5000)
+	assert.True(t, errors.Is(err, ErrInvalidLevel))
+	buf.Reset()
+	w, err = NewLineWriter(lgr, levels.Silent, Config{
+		NoPanic:   true,
+		LevelFunc: MatchPrefix(map[string]levels.Type{"F ": levels.Fatal}),
+	})
+	assert.NoError(t, err)
+	_, _ = io.WriteString(w, "discarded\nF exiting\n")
+	assert.Equal(t, `level=error msg="exiting"`, buf.String())
+}
+
+func TestScan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf)
+	lgr.Level(levels.Trace)
+
+	lines := strings.Join([]string{
+		`{"level":"warn","msg":"careful","user":"john"}`,
+		`level=debug msg="with spaces" n=1`,
+		`{"level":50,"message":"pino"}`,
+		`{"level":"fatal","msg":"exiting"}`,
+		`plain text`,
+		`E plain error`,
+		`a=b but not logfmt`,
+		``,
+	}, "\r\n") + "last"
+	err := Scan(strings.NewReader(lines), lgr, levels.Info, ReaderConfig{
+		Name:      "child",
+		LevelFunc: MatchPrefix(map[string]levels.Type{"E ": levels.Error}),
+	})
+	assert.NoError(t, err)
+	out := strings.Split(strings.ReplaceAll(buf.String(), "level=", "\nlevel="), "\n")[1:]
+	assert.Len(t, out, 8)
+	assert.Contains(t, out[0], `level=warning msg="careful"`)
+	assert.Contains(t, out[0], `user="john"`)
+	assert.Contains(t, out[0], `prefix="child"`)
+	assert.NotContains(t, out[0], `msg="careful" level`)
+	assert.Contains(t, out[1], `level=debug msg="with spaces"`)
+	assert.Contains(t, out[1], `n="1"`)
+	assert.Contains(t, out[2], `level=error msg="pino"`)
+	assert.Contains(t, out[3], `level=error msg="exiting"`, "not exiting")
+	assert.Contains(t, out[4], `level=info msg="plain text"`)
+	assert.Contains(t, out[5], `level=error msg="plain error"`)
+	assert.Contains(t, out[6], `level=info msg="a=b but not logfmt"`)
+	assert.Contains(t, out[7], `level=info msg="last"`)
+
+	// Test the abbreviated levels
+	buf.Reset()
+	assert.NoError(t, Scan(strings.NewReader("level=ERRO msg=logrus\nlevel=WRN msg=zerolog"), lgr, levels.Info))
+	assert.Equal(t, `level=error msg="logrus"level=warning msg="zerolog"`, buf.String())
+
+	// Test the formats
 	buf.Reset()
-	t.Error("Not happening Logger failed to panic")
+	assert.NoError(t, Scan(strings.NewReader(`{"msg":"json"}`), lgr, levels.Info, ReaderConfig{Format: FormatText}))
+	assert.Contains(t, buf.String(), `msg="{\"msg\":\"json\"}"`)
+	buf.Reset()
+	assert.NoError(t, Scan(strings.NewReader(`a=b c="d e"`), lgr, levels.Info, ReaderConfig{Format: FormatLogfmt}))
+	assert.Contains(t, buf.String(), `a="b"`)
+	assert.Contains(t, buf.String(), `c="d e"`)
+	buf.Reset()
+	assert.NoError(t, Scan(strings.NewReader(`a=b`), lgr, levels.Info))
+	assert.Equal(t, `level=info msg="a=b"`, buf.String(), "logfmt without message nor level")
+
+	// Test the long lines are split
+	buf.Reset()
+	assert.NoError(t, Scan(strings.NewReader(strings.Repeat("x", 40)), lgr, levels.Info, ReaderConfig{MaxLineSize: 16}))
+	assert.Equal(t, 3, strings.Count(buf.String(), "level=info"))
+
+	// Test the invalid and silent levels
+	assert.True(t, errors.Is(Scan(strings.NewReader("line"), lgr, 5000), ErrInvalidLevel))
+	buf.Reset()
+	assert.NoError(t, Scan(strings.NewReader("discarded\nlevel=warn msg=kept"), lgr, levels.Silent))
+	assert.Equal(t, `level=warning msg="kept"`, buf.String())
+}
+
+func TestRun(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf) // not safe for concurrent use
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	assert.NoError(t, Run(cmd, lgr, ReaderConfig{Name: "helper"}))
+	for _, field := range []string{
+		`level=info msg="to stdout"`,
+		`stream="stdout"`,
+		`level=error msg="to stderr"`,
+		`stream="stderr"`,
+		`level=warning msg="structured"`,
+		`prefix="helper"`,
+	} {
+		assert.Contains(t, buf.String(), field)
+	}
+
+	cmd = exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1", "HELPER_EXIT=3")
+	err := Run(cmd, lgr)
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+
+	_, err = Start(exec.Command("/nonexistent"), lgr)
+	assert.Error(t, err)
+
+	cmd = exec.Command(os.Args[0])
+	cmd.Stderr = &bytes.Buffer{}
+	_, err = Start(cmd, lgr)
+	assert.EqualError(t, err, "exec: Stderr already set")
+}
+
+// TestHelperProcess is the command run by TestRun.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Println("to stdout")
+	fmt.Fprintln(os.Stderr, "to stderr")
+	fmt.Fprintln(os.Stderr, `{"level":"warn","msg":"structured"}`)
+	code, _ := strconv.Atoi(os.Getenv("HELPER_EXIT"))
+	os.Exit(code)
 }
 
 // logt is a test Logger.
@@ -0,0 +1,93 @@
+package io
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// LineWriter is an io.WriteCloser logging what is written to it line by line:
+// partial lines are kept until their newline or Close, and trailing newlines
+// are stripped. It is safe for concurrent use.
+type LineWriter struct {
+	logger log.Logger // nil for the current package level logger
+	level  levels.Type
+	cfg    Config
+	mu     sync.Mutex
+	buf    []byte
+}
+
+// NewLineWriter creates a new LineWriter. It accepts a logger and the level
+// of the lines, unless detected by LevelFunc, see NewWriterE.
+func NewLineWriter(lgr log.Logger, level levels.Type, config ...Config) (*LineWriter, error) {
+	if err := validate(level); err != nil {
+		return nil, err
+	}
+	return &LineWriter{
+		logger: lgr,
+		level:  level,
+		cfg:    configDefault(config...),
+	}, nil
+}
+
+// NewCurrentLineWriter creates a new LineWriter using the current package
+// level logger, see NewCurrentWriterE.
+func NewCurrentLineWriter(level levels.Type, config ...Config) (*LineWriter, error) {
+	return NewLineWriter(nil, level, config...)
+}
+
+// Write is the write method from the io.Writer interface in the standard lib
+func (w *LineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) >= w.cfg.MaxLineSize {
+		w.log(w.buf[:w.cfg.MaxLineSize])
+		w.buf = w.buf[w.cfg.MaxLineSize:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil // release the consumed buffer
+	}
+	return len(p), nil
+}
+
+// Close logs the partial line left, if any.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// log logs a line, without its carriage return, at its detected level or
+// else at the level of the writer. Empty lines are skipped.
+func (w *LineWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+	msg, level := string(line), w.level
+	if w.cfg.LevelFunc != nil {
+		if l, m, ok := w.cfg.LevelFunc(msg); ok {
+			level, msg = l, m
+		}
+	}
+	lgr := w.logger
+	if lgr == nil {
+		lgr = log.Current
+	}
+	_ = write(lgr, level, w.cfg.NoPanic, msg, nil) // invalid detected levels are dropped
+}
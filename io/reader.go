@@ -0,0 +1,324 @@
+package io
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Format is the format of the lines read by Scan.
+type Format int
+
+// Formats of the lines.
+const (
+	FormatAuto   Format = iota // JSON or logfmt when lines parse as such, or else text
+	FormatText                 // Text, with the level of the reader or of LevelFunc
+	FormatJSON                 // JSON objects, i.e. {"level":"info","msg":"hello"}
+	FormatLogfmt               // Logfmt, i.e. level=info msg=hello
+)
+
+// Field keys of the log entries, and of the lines parsed.
+const (
+	KeyPrefix = "prefix"
+	KeyStream = "stream"
+)
+
+var (
+	// msgKeys and levelKeys are the keys of the messages and levels of the
+	// lines parsed, i.e. of zap, logrus, zerolog, hclog, slog or pino.
+	msgKeys   = []string{"msg", "message", "@message"}
+	levelKeys = []string{"level", "lvl", "severity", "@level"}
+)
+
+// ReaderConfig defines the config for Scan, Start and Run.
+type ReaderConfig struct {
+	// Name is the prefix field of the entries, i.e. the name of the command.
+	//
+	// Optional. Default: ""
+	Name string
+
+	// Format is the format of the lines.
+	//
+	// Optional. Default: FormatAuto
+	Format Format
+
+	// LevelFunc returns the level of a text line when detected, and the line
+	// without its level prefix, i.e. MatchPrefix.
+	//
+	// Optional. Default: nil, the level of the reader
+	LevelFunc func(line string) (levels.Type, string, bool)
+
+	// MaxLineSize is the size from which lines are split.
+	//
+	// Optional. Default: 65536
+	MaxLineSize int
+}
+
+// ReaderConfigDefault is the default config
+var ReaderConfigDefault = ReaderConfig{
+	Name:        "",
+	Format:      FormatAuto,
+	LevelFunc:   nil,
+	MaxLineSize: 64 * 1024,
+}
+
+// Helper function to set default values
+func readerConfigDefault(config ...ReaderConfig) ReaderConfig {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ReaderConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.MaxLineSize <= 0 {
+		cfg.MaxLineSize = ReaderConfigDefault.MaxLineSize
+	}
+	return cfg
+}
+
+// Scan logs the lines read from r through lgr, until r returns io.EOF, which
+// is not returned. The lines without level are logged at level, or discarded
+// at Silent. Panic and Fatal lines are logged at Error, so as not to exit.
+func Scan(r io.Reader, lgr log.Logger, level levels.Type, config ...ReaderConfig) error {
+	if err := validate(level); err != nil {
+		return err
+	}
+	return scan(r, lgr, level, nil, readerConfigDefault(config...), &sync.Mutex{})
+}
+
+// Start starts cmd, which must not have its Stdout nor Stderr set, logging
+// their lines through lgr, at Info and Error respectively unless their lines
+// have a level, see Scan. Both are read concurrently, but lgr is called by one
+// goroutine at a time, so it need not be safe for concurrent use. The returned
+// wait function waits for their last lines to be logged, and then for cmd to
+// exit, see exec.Cmd.Wait.
+func Start(cmd *exec.Cmd, lgr log.Logger, config ...ReaderConfig) (wait func() error, err error) {
+	cfg := readerConfigDefault(config...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		_ = stdout.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex // serialises the calls to lgr
+	errs := make([]error, 2)
+	for i, stream := range []struct {
+		r     io.Reader
+		name  string
+		level levels.Type
+	}{
+		{stdout, "stdout", levels.Info},
+		{stderr, "stderr", levels.Error},
+	} {
+		wg.Add(1)
+		go func(i int, r io.Reader, name string, level levels.Type) {
+			defer wg.Done()
+			errs[i] = scan(r, lgr, level, log.Map{KeyStream: name}, cfg, &mu)
+		}(i, stream.r, stream.name, stream.level)
+	}
+	return func() error {
+		wg.Wait() // before cmd.Wait closes the pipes
+		err := cmd.Wait()
+		for _, e := range errs {
+			if err == nil {
+				err = e
+			}
+		}
+		return err
+	}, nil
+}
+
+// Run runs cmd, logging its stdout and stderr through lgr, see Start.
+func Run(cmd *exec.Cmd, lgr log.Logger, config ...ReaderConfig) error {
+	wait, err := Start(cmd, lgr, config...)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+// scan logs the lines read from r, with fields, holding mu while logging.
+func scan(r io.Reader, lgr log.Logger, level levels.Type, fields log.Map, cfg ReaderConfig, mu *sync.Mutex) error {
+	if cfg.Name != "" {
+		fields = log.Merge(fields, log.Map{KeyPrefix: cfg.Name})
+	}
+	br := bufio.NewReaderSize(r, cfg.MaxLineSize)
+	for {
+		line, err := br.ReadSlice('\n')
+		if len(line) > 0 {
+			mu.Lock()
+			logLine(lgr, level, fields, cfg, strings.TrimRight(string(line), "\r\n"))
+			mu.Unlock()
+		}
+		switch {
+		case err == nil, errors.Is(err, bufio.ErrBufferFull): // long lines are split
+		case errors.Is(err, io.EOF), errors.Is(err, os.ErrClosed): // i.e. the command exited
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// logLine logs a line, parsed as per the format.
+func logLine(lgr log.Logger, level levels.Type, fields log.Map, cfg ReaderConfig, line string) {
+	if line == "" {
+		return
+	}
+	msg := line
+	parsed, ok := parse(line, cfg.Format)
+	if ok {
+		msg = ""
+		if key, val, found := lookup(parsed, msgKeys); found {
+			msg = fmt.Sprint(val)
+			delete(parsed, key)
+		}
+		if key, val, found := lookup(parsed, levelKeys); found {
+			if l, ok := parseLevel(val); ok {
+				level = l
+				delete(parsed, key)
+			}
+		}
+		fields = log.Merge(parsed, fields)
+	} else if cfg.LevelFunc != nil {
+		if l, m, ok := cfg.LevelFunc(line); ok {
+			level, msg = l, m
+		}
+	}
+	_ = write(lgr, level, true, msg, fields) // invalid detected levels are dropped
+}
+
+// parse parses a line as per the format, returning its fields.
+func parse(line string, format Format) (log.Map, bool) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(line)
+	case FormatLogfmt:
+		return parseLogfmt(line)
+	case FormatAuto:
+		if m, ok := parseJSON(line); ok {
+			return m, true
+		}
+		if m, ok := parseLogfmt(line); ok {
+			if _, _, found := lookup(m, msgKeys); found {
+				return m, true
+			}
+			if _, _, found := lookup(m, levelKeys); found {
+				return m, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// parseJSON parses a JSON object.
+func parseJSON(line string) (log.Map, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return nil, false
+	}
+	m := log.Map{}
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// parseLogfmt parses logfmt pairs, with quoted values or not.
+func parseLogfmt(line string) (log.Map, bool) {
+	m := log.Map{}
+	s := strings.TrimSpace(line)
+	for s != "" {
+		i := strings.IndexByte(s, '=')
+		if i <= 0 || strings.ContainsAny(s[:i], " \t\"") {
+			return nil, false
+		}
+		key := s[:i]
+		s = s[i+1:]
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			j := 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, false
+			}
+			v, err := strconv.Unquote(s[:j+1])
+			if err != nil {
+				return nil, false
+			}
+			val, s = v, s[j+1:]
+		} else {
+			j := strings.IndexAny(s, " \t")
+			if j < 0 {
+				j = len(s)
+			}
+			val, s = s[:j], s[j:]
+		}
+		if s != "" && s[0] != ' ' && s[0] != '\t' {
+			return nil, false
+		}
+		m[key] = val
+		s = strings.TrimLeft(s, " \t")
+	}
+	return m, len(m) > 0
+}
+
+// lookup returns the first of the keys found in m.
+func lookup(m log.Map, keys []string) (string, interface{}, bool) {
+	for _, key := range keys {
+		if val, ok := m[key]; ok {
+			return key, val, true
+		}
+	}
+	return "", nil, false
+}
+
+// parseLevel parses the level of a line, a name or a pino number.
+func parseLevel(val interface{}) (levels.Type, bool) {
+	switch v := val.(type) {
+	case string:
+		if level, ok := levels.FromName(v); ok {
+			return level, true
+		}
+	case float64: // pino: 10 trace, 20 debug, 30 info, 40 warn, 50 error, 60 fatal
+		switch {
+		case v < 20:
+			return levels.Trace, true
+		case v < 30:
+			return levels.Debug, true
+		case v < 40:
+			return levels.Info, true
+		case v < 50:
+			return levels.Warn, true
+		case v < 60:
+			return levels.Error, true
+		default:
+			return levels.Fatal, true
+		}
+	}
+	return levels.Info, false
+}
@@ -0,0 +1,101 @@
+package rotate
+
+import (
+	"os"
+	"time"
+)
+
+// Config defines the config for the rotating file writer.
+type Config struct {
+	// MaxSize is the maximum size in bytes of the log file before it gets
+	// rotated. A negative value disables size based rotation.
+	//
+	// Optional. Default: 100 * 1024 * 1024 (100MB)
+	MaxSize int64
+
+	// Interval is the maximum time span covered by a single log file before
+	// it gets rotated, i.e. time.Hour or 24 * time.Hour. A zero value
+	// disables time based rotation.
+	//
+	// Optional. Default: 0
+	Interval time.Duration
+
+	// MaxAge is the maximum time to retain old log files, based on the
+	// timestamp encoded in their filename. A zero value does not remove old
+	// log files based on age.
+	//
+	// Optional. Default: 0
+	MaxAge time.Duration
+
+	// MaxBackups is the maximum number of old log files to retain. A zero
+	// value retains all old log files (though MaxAge may still cause them to
+	// get deleted).
+	//
+	// Optional. Default: 0
+	MaxBackups int
+
+	// Compress determines if the rotated log files should be compressed
+	// using gzip, in the background.
+	//
+	// Optional. Default: false
+	Compress bool
+
+	// LocalTime determines if the time used for formatting the timestamps in
+	// backup files is the computer's local time. Otherwise, UTC is used.
+	//
+	// Optional. Default: false
+	LocalTime bool
+
+	// ReopenOnSIGHUP closes and reopens the log file whenever the process
+	// receives a SIGHUP signal, for compatibility with logrotate and the
+	// like, where the file is moved away by an external tool.
+	//
+	// Optional. Default: false
+	ReopenOnSIGHUP bool
+
+	// FileMode is the permission mode used when creating new log files.
+	//
+	// Optional. Default: 0644
+	FileMode os.FileMode
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	MaxSize:        100 * 1024 * 1024,
+	Interval:       0,
+	MaxAge:         0,
+	MaxBackups:     0,
+	Compress:       false,
+	LocalTime:      false,
+	ReopenOnSIGHUP: false,
+	FileMode:       0644,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = ConfigDefault.MaxSize
+	}
+	if cfg.Interval < 0 {
+		cfg.Interval = 0
+	}
+	if cfg.MaxAge < 0 {
+		cfg.MaxAge = 0
+	}
+	if cfg.MaxBackups < 0 {
+		cfg.MaxBackups = 0
+	}
+	if cfg.FileMode == 0 {
+		cfg.FileMode = ConfigDefault.FileMode
+	}
+	return cfg
+}
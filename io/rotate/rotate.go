@@ -0,0 +1,417 @@
+// Package rotate provides an io.Writer that writes to a log file and rotates
+// it by size and/or time, keeping a limited number of backups which can
+// optionally be compressed. It can be plugged into any io.Writer based
+// output, for example:
+//
+//	import(
+//	    stdlog "log"
+//	    "github.com/roninzo/log/impl/std"
+//	    "github.com/roninzo/log/io/rotate"
+//	)
+//
+//	func main() {
+//	    w, err := rotate.New("/var/log/app.log", rotate.Config{MaxBackups: 3})
+//	    if err != nil {
+//	        panic(err)
+//	    }
+//	    defer w.Close()
+//	    lgr := std.New(stdlog.New(w, "", stdlog.LstdFlags))
+//	    lgr.Info("foo")
+//	}
+//
+// Rotated files are renamed using the original filename with a timestamp
+// inserted between the name and the extension, i.e. app-2006-01-02T15-04-05.000.log
+// and app-2006-01-02T15-04-05.000.log.gz once compressed.
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// currentTime exists so it can be mocked out by tests.
+var currentTime = time.Now
+
+// Ensure Writer satisfies the io.WriteCloser interface.
+var _ io.WriteCloser = (*Writer)(nil)
+
+// Writer is an io.WriteCloser that writes to the named file and rotates it
+// according to its Config. It is safe for concurrent use.
+type Writer struct {
+	Config
+
+	filename string
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	deadline time.Time // next time based rotation
+	signals  chan os.Signal
+	done     chan struct{}
+	cleanups sync.WaitGroup // pending cleanups
+	cleaning sync.Mutex     // serialises the cleanups
+}
+
+// New creates a rotating file writer for the named file. The file, as well as
+// its parent directory, is created when missing. If the file already exists,
+// new writes are appended to it.
+func New(filename string, config ...Config) (*Writer, error) {
+	if filename == "" {
+		return nil, errors.New("rotate: missing filename")
+	}
+	w := &Writer{
+		Config:   configDefault(config...),
+		filename: filename,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if w.ReopenOnSIGHUP {
+		w.signals = make(chan os.Signal, 1)
+		w.done = make(chan struct{})
+		signal.Notify(w.signals, syscall.SIGHUP)
+		go w.watch(w.signals, w.done)
+	}
+	return w, nil
+}
+
+// Filename returns the name of the file currently written to.
+func (w *Writer) Filename() string {
+	return w.filename
+}
+
+// Write implements io.Writer. If a write would cause the log file to exceed
+// MaxSize, or if the current file is older than Interval, the file is rotated
+// first. A single write larger than MaxSize is written as is.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err = w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err = w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer, and closes the current log file once the
+// pending cleanups are done. It also stops listening to SIGHUP signals.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cleanups.Wait()
+	if w.signals != nil {
+		signal.Stop(w.signals)
+		close(w.done)
+		w.signals = nil
+	}
+	return w.close()
+}
+
+// Rotate forces the rotation of the current log file, regardless of its size
+// or age.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rotate()
+}
+
+// Reopen closes the current log file and opens the file by its name again.
+// This is useful when the file was moved away by an external tool, like
+// logrotate, so that writing continues into a fresh file.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.close(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// watch reopens the log file each time a SIGHUP signal is received.
+func (w *Writer) watch(signals <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-signals:
+			w.mu.Lock()
+			if w.signals != nil { // not closed meanwhile
+				_ = w.close()
+				_ = w.open()
+			}
+			w.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (w *Writer) shouldRotate(n int64) bool {
+	switch {
+	case w.MaxSize > 0 && w.size > 0 && w.size+n > w.MaxSize:
+		return true
+	case w.Interval > 0 && !currentTime().Before(w.deadline):
+		return true
+	default:
+		return false
+	}
+}
+
+// open opens the log file in append mode, creating it if necessary.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return fmt.Errorf("rotate: cannot create directory: %w", err)
+	}
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.FileMode)
+	if err != nil {
+		return fmt.Errorf("rotate: cannot open file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("rotate: cannot stat file: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	w.deadline = w.nextDeadline(currentTime())
+	if w.size > 0 {
+		w.deadline = w.nextDeadline(info.ModTime())
+	}
+	return nil
+}
+
+func (w *Writer) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate closes the current file, renames it to a backup name, opens a new
+// file and cleans up backups according to MaxBackups, MaxAge and Compress, in
+// the background not to block the writes while compressing.
+func (w *Writer) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(w.filename); err == nil {
+		if err := os.Rename(w.filename, w.backupName()); err != nil {
+			return fmt.Errorf("rotate: cannot rename file: %w", err)
+		}
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	if w.MaxBackups == 0 && w.MaxAge == 0 && !w.Compress {
+		return nil
+	}
+	now := w.timestamp()
+	w.cleanups.Add(1)
+	go func() {
+		defer w.cleanups.Done()
+		w.cleaning.Lock()
+		defer w.cleaning.Unlock()
+		if err := w.cleanup(now); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}()
+	return nil
+}
+
+func (w *Writer) nextDeadline(from time.Time) time.Time {
+	if w.Interval <= 0 {
+		return time.Time{}
+	}
+	return from.Truncate(w.Interval).Add(w.Interval)
+}
+
+func (w *Writer) location() *time.Location {
+	if w.LocalTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+func (w *Writer) timestamp() time.Time {
+	return currentTime().In(w.location())
+}
+
+// backupName returns a new, unused, backup filename for the log file.
+func (w *Writer) backupName() string {
+	dir, prefix, ext := w.parts()
+	t := w.timestamp()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		_, err := os.Stat(name)
+		_, errgz := os.Stat(name + compressSuffix)
+		if os.IsNotExist(err) && os.IsNotExist(errgz) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// parts splits the log filename into its directory, backup prefix and
+// extension.
+func (w *Writer) parts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+// backup is an old log file.
+type backup struct {
+	path       string
+	time       time.Time
+	compressed bool
+}
+
+// Backups returns the paths of the old log files, newest first, once the
+// pending cleanups are done.
+func (w *Writer) Backups() ([]string, error) {
+	w.mu.Lock()
+	w.cleanups.Wait()
+	w.mu.Unlock()
+	list, err := w.backups()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(list))
+	for _, b := range list {
+		paths = append(paths, b.path)
+	}
+	return paths, nil
+}
+
+// backups returns the old log files found next to the log file, newest first.
+func (w *Writer) backups() ([]backup, error) {
+	dir, prefix, ext := w.parts()
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("rotate: cannot read directory: %w", err)
+	}
+	var list []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		compressed := strings.HasSuffix(name, ext+compressSuffix)
+		trimmed := strings.TrimSuffix(name, compressSuffix)
+		if !strings.HasPrefix(trimmed, prefix) || !strings.HasSuffix(trimmed, ext) {
+			continue
+		}
+		stamp := trimmed[len(prefix) : len(trimmed)-len(ext)]
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, w.location())
+		if err != nil {
+			continue
+		}
+		list = append(list, backup{
+			path:       filepath.Join(dir, name),
+			time:       t,
+			compressed: compressed,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].time.After(list[j].time)
+	})
+	return list, nil
+}
+
+// cleanup removes the backups exceeding MaxBackups or older than MaxAge at
+// now, and compresses the remaining ones when Compress is set. Its errors are
+// written to stderr by rotate.
+func (w *Writer) cleanup(now time.Time) error {
+	list, err := w.backups()
+	if err != nil {
+		return err
+	}
+	var cutoff time.Time
+	if w.MaxAge > 0 {
+		cutoff = now.Add(-w.MaxAge)
+	}
+	var errs []string
+	for i, b := range list {
+		switch {
+		case w.MaxBackups > 0 && i >= w.MaxBackups, w.MaxAge > 0 && b.time.Before(cutoff):
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+		case w.Compress && !b.compressed:
+			if err := compress(b.path); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rotate: cleanup failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// compress gzips the named file, and removes it once successfully compressed.
+func compress(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(name)
+}
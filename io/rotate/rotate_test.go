@@ -0,0 +1,164 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log/impl/std"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTime mocks out currentTime for the duration of a test.
+func fakeTime(t *testing.T, now time.Time) *time.Time {
+	def := currentTime
+	currentTime = func() time.Time { return now }
+	t.Cleanup(func() { currentTime = def })
+	return &now
+}
+
+func read(t *testing.T, name string) string {
+	b, err := ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestRotateSize(t *testing.T) {
+	fakeTime(t, time.Date(2022, 4, 20, 13, 6, 6, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	w, err := New(name, Config{MaxSize: 10, MaxBackups: 2})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	for _, s := range []string{"12345\n", "abcde\n", "fghij\n", "klmno\n"} {
+		n, err := io.WriteString(w, s)
+		assert.NoError(t, err)
+		assert.Equal(t, len(s), n)
+	}
+	assert.Equal(t, "klmno\n", read(t, name))
+
+	backups, err := w.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2) // oldest one "12345\n" was removed
+	assert.Equal(t, "fghij\n", read(t, backups[0]))
+	assert.Equal(t, "abcde\n", read(t, backups[1]))
+	assert.True(t, strings.HasSuffix(backups[1], "app-2022-04-20T13-06-06.001.log"), backups[1])
+}
+
+func TestRotateInterval(t *testing.T) {
+	now := fakeTime(t, time.Date(2022, 4, 20, 13, 6, 6, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := New(name, Config{MaxSize: -1, Interval: time.Hour})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	_, _ = io.WriteString(w, "foo\n")
+	*now = now.Add(30 * time.Minute)
+	_, _ = io.WriteString(w, "bar\n")
+	assert.Equal(t, "foo\nbar\n", read(t, name))
+
+	*now = now.Add(30 * time.Minute) // 14:06:06, past the 14:00:00 deadline
+	_, _ = io.WriteString(w, "baz\n")
+	assert.Equal(t, "baz\n", read(t, name))
+
+	backups, err := w.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	assert.Equal(t, "foo\nbar\n", read(t, backups[0]))
+}
+
+func TestRotateMaxAge(t *testing.T) {
+	now := fakeTime(t, time.Date(2022, 4, 20, 13, 6, 6, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := New(name, Config{MaxAge: 24 * time.Hour})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	_, _ = io.WriteString(w, "foo\n")
+	assert.NoError(t, w.Rotate())
+	*now = now.Add(12 * time.Hour)
+	_, _ = io.WriteString(w, "bar\n")
+	assert.NoError(t, w.Rotate())
+
+	backups, err := w.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2)
+
+	*now = now.Add(20 * time.Hour)
+	_, _ = io.WriteString(w, "baz\n")
+	assert.NoError(t, w.Rotate())
+
+	backups, err = w.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2) // "foo\n" is now older than a day
+	assert.Equal(t, "baz\n", read(t, backups[0]))
+	assert.Equal(t, "bar\n", read(t, backups[1]))
+}
+
+func TestRotateCompress(t *testing.T) {
+	fakeTime(t, time.Date(2022, 4, 20, 13, 6, 6, 0, time.UTC))
+	name := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := New(name, Config{Compress: true})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	_, _ = io.WriteString(w, "foo\n")
+	assert.NoError(t, w.Rotate())
+
+	backups, err := w.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".log.gz"), backups[0])
+
+	f, err := os.Open(backups[0])
+	assert.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo\n", string(b))
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "app.log.1")
+
+	w, err := New(name, Config{ReopenOnSIGHUP: true})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	lgr := std.New(stdlog.New(w, "", 0))
+	lgr.Info("foo")
+	assert.NoError(t, os.Rename(name, moved)) // i.e. logrotate
+	lgr.Info("bar")
+	assert.NoError(t, w.Reopen())
+	lgr.Info("baz")
+
+	assert.Equal(t, "[INFO]  foo\n[INFO]  bar\n", read(t, moved))
+	assert.Equal(t, "[INFO]  baz\n", read(t, name))
+
+	// Same, triggered by a SIGHUP signal.
+	assert.NoError(t, os.Rename(name, moved))
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Signal(syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(name)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	lgr.Info("qux")
+	assert.Equal(t, "[INFO]  qux\n", read(t, name))
+}
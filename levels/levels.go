@@ -51,6 +51,40 @@ func FromString(s string) Type {
 	}
 }
 
+// names are the level names and abbreviations used by loggers, in lowercase,
+// i.e. by logrus, zap, zerolog, hclog, slog or syslog.
+var names = map[string]Type{
+	"trace":    Trace,
+	"trc":      Trace,
+	"debug":    Debug,
+	"dbg":      Debug,
+	"info":     Info,
+	"inf":      Info,
+	"notice":   Info,
+	"warn":     Warn,
+	"warning":  Warn,
+	"wrn":      Warn,
+	"error":    Error,
+	"err":      Error,
+	"erro":     Error,
+	"dpanic":   Panic,
+	"panic":    Panic,
+	"fatal":    Fatal,
+	"crit":     Fatal,
+	"critical": Fatal,
+}
+
+// FromName returns the level of a level name as logged by loggers, including
+// their abbreviations, and whether it is known. This facilitates reading the
+// levels of log lines, in which Silent never appears.
+// Example:
+// 	"WRN" => level.Warn, true
+// The Level string argument `s` is NOT "case-sensitive".
+func FromName(s string) (Type, bool) {
+	level, ok := names[strings.ToLower(strings.TrimSpace(s))]
+	return level, ok
+}
+
 // FromDebug returns the debug level type if debug argument is true,
 // or the info level type if debug argument if false or missing.
 // This facilitates setting the log level via config or environment
@@ -237,3 +237,27 @@ func (l logt) unmap(fields Map) string {
 	}
 	return strings.TrimSuffix(ret, " ")
 }
+
+func TestWith(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lgr := newTestLogger(buf)
+	lgr.Level(levels.Trace)
+
+	w := With(lgr, Map{"foo": "bar"})
+	assert.Equal(t, Map{"foo": "bar"}, Fields(w))
+	assert.Nil(t, Fields(lgr))
+
+	w.Info("test info")
+	assert.Contains(t, buf.String(), `level=info msg="test info" foo=bar`)
+	buf.Reset()
+
+	w.Warnf("Hello %s", "World", Map{"foo": "baz"}) // message fields take precedence
+	assert.Contains(t, buf.String(), `level=warning msg="Hello World" foo=baz`)
+	buf.Reset()
+
+	w = With(w, Map{"baz": "qux"}) // not nested
+	assert.Equal(t, Map{"foo": "bar", "baz": "qux"}, Fields(w))
+
+	w.Prefix("roninzo")
+	assert.Equal(t, "roninzo", lgr.Prefix())
+}
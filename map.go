@@ -5,6 +5,10 @@ var (
 	noMap = Map{}
 )
 
+// Redacted replaces the values which must not be logged, i.e. secrets, in the
+// fields logged by the middlewares and interfaces.
+const Redacted = "[REDACTED]"
+
 // Map is a shortcut for map[string]interface{} key, values, useful for JSON
 // returns. Where the values can be any Go type, but convertable to a string.
 type Map map[string]interface{}
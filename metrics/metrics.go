@@ -0,0 +1,247 @@
+// Package metrics provides a wrapper for any log.Logger counting log entries
+// per level and per prefix, as well as the entries discarded for being below
+// the logger level or by sampling. Counters are exposed through an
+// http.Handler in the Prometheus text exposition format, for example:
+//
+//	import(
+//	    "net/http"
+//	    "github.com/roninzo/log"
+//	    "github.com/roninzo/log/metrics"
+//	)
+//
+//	func main() {
+//	    log.Current = metrics.New(log.Current)
+//	    http.Handle("/metrics", metrics.Handler())
+//	    log.Error("foo") // => log_entries_total{level="error",prefix=""} 1
+//	}
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Config defines the config for the metrics logger.
+type Config struct {
+	// Registry holds the counters.
+	//
+	// Optional. Default: DefaultRegistry
+	Registry *Registry
+
+	// Sampling keeps only 1 out of N entries for the given levels, i.e.
+	// map[levels.Type]uint64{levels.Debug: 100}. Panic and Fatal entries are
+	// never sampled.
+	//
+	// Optional. Default: nil
+	Sampling map[levels.Type]uint64
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Registry: DefaultRegistry,
+	Sampling: nil,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Registry == nil {
+		cfg.Registry = ConfigDefault.Registry
+	}
+	return cfg
+}
+
+// Logger counts the entries logged through the wrapped log.Logger.
+type Logger struct {
+	log.Logger
+	Config
+
+	seen *[levels.Silent]uint64 // entries seen per level, for sampling
+}
+
+// New wraps a logger, counting its entries in the configured registry. The
+// prefix label is the prefix of the wrapped logger at the time of logging.
+func New(lgr log.Logger, config ...Config) *Logger {
+	l := &Logger{
+		Logger: lgr,
+		Config: configDefault(config...),
+		seen:   new([levels.Silent]uint64),
+	}
+	l.Registry.register(l.prefix())
+	return l
+}
+
+// Named returns a metrics logger wrapping the named child of the wrapped
+// logger, sharing the registry and the sampling counts. The wrapped logger
+// must have a Named method returning a log.Logger, as all the implementations
+// do; otherwise the child wraps the same logger.
+func (l *Logger) Named(name string) *Logger {
+	child := &Logger{
+		Logger: l.Logger,
+		Config: l.Config,
+		seen:   l.seen,
+	}
+	m := reflect.ValueOf(l.Logger).MethodByName("Named")
+	if m.IsValid() && m.Type().NumIn() == 1 && m.Type().In(0).Kind() == reflect.String && m.Type().NumOut() == 1 {
+		if lgr, ok := m.Call([]reflect.Value{reflect.ValueOf(name)})[0].Interface().(log.Logger); ok {
+			child.Logger = lgr
+		}
+	}
+	child.Registry.register(child.prefix())
+	return child
+}
+
+func (l *Logger) Options(funcs ...func(*Logger) *Logger) *Logger {
+	for _, f := range funcs {
+		f(l)
+	}
+	return l
+}
+
+func (l *Logger) WithLevel(level levels.Type) *Logger {
+	l.Level(level)
+	return l
+}
+
+func (l *Logger) WithLevelFromDebug(debug bool) *Logger {
+	switch debug {
+	case true:
+		l.Level(levels.Debug)
+	default:
+		l.Level(levels.Info)
+	}
+	return l
+}
+
+// Prefix implements log.Logger. Renaming the wrapped logger moves the series
+// created for its previous prefix, as long as they are unused.
+func (l *Logger) Prefix(name ...string) string {
+	previous := l.prefix()
+	prefix := l.Logger.Prefix(name...)
+	if current := l.prefix(); current != previous {
+		l.Registry.unregister(previous)
+		l.Registry.register(current)
+	}
+	return prefix
+}
+
+func (l *Logger) Trace(msg ...interface{}) {
+	if l.allow(levels.Trace) {
+		l.Logger.Trace(msg...)
+	}
+}
+
+func (l *Logger) Debug(msg ...interface{}) {
+	if l.allow(levels.Debug) {
+		l.Logger.Debug(msg...)
+	}
+}
+
+func (l *Logger) Info(msg ...interface{}) {
+	if l.allow(levels.Info) {
+		l.Logger.Info(msg...)
+	}
+}
+
+func (l *Logger) Warn(msg ...interface{}) {
+	if l.allow(levels.Warn) {
+		l.Logger.Warn(msg...)
+	}
+}
+
+func (l *Logger) Error(msg ...interface{}) {
+	if l.allow(levels.Error) {
+		l.Logger.Error(msg...)
+	}
+}
+
+func (l *Logger) Panic(msg ...interface{}) {
+	if l.allow(levels.Panic) {
+		l.Logger.Panic(msg...)
+	}
+}
+
+func (l *Logger) Fatal(msg ...interface{}) {
+	if l.allow(levels.Fatal) {
+		l.Logger.Fatal(msg...)
+	}
+}
+
+func (l *Logger) Tracef(template string, args ...interface{}) {
+	if l.allow(levels.Trace) {
+		l.Logger.Tracef(template, args...)
+	}
+}
+
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	if l.allow(levels.Debug) {
+		l.Logger.Debugf(template, args...)
+	}
+}
+
+func (l *Logger) Infof(template string, args ...interface{}) {
+	if l.allow(levels.Info) {
+		l.Logger.Infof(template, args...)
+	}
+}
+
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	if l.allow(levels.Warn) {
+		l.Logger.Warnf(template, args...)
+	}
+}
+
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	if l.allow(levels.Error) {
+		l.Logger.Errorf(template, args...)
+	}
+}
+
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	if l.allow(levels.Panic) {
+		l.Logger.Panicf(template, args...)
+	}
+}
+
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	if l.allow(levels.Fatal) {
+		l.Logger.Fatalf(template, args...)
+	}
+}
+
+// allow counts an entry, and reports whether it should be logged.
+func (l *Logger) allow(level levels.Type) bool {
+	s := series{metricEntries, level.String(), l.prefix()}
+	if level < l.Logger.Level() { // Trace(0) < Info(2) => no logging
+		s.metric = metricDropped
+		l.Registry.inc(s)
+		return false
+	}
+	if n := l.Sampling[level]; n > 1 && level < levels.Panic {
+		if atomic.AddUint64(&l.seen[level], 1)%n != 1 {
+			s.metric = metricSampled
+			l.Registry.inc(s)
+			return false
+		}
+	}
+	l.Registry.inc(s)
+	return true
+}
+
+// prefix returns the prefix of the wrapped logger, without trailing ": " as
+// used by the std implementation.
+func (l *Logger) prefix() string {
+	return strings.TrimSuffix(strings.TrimSpace(l.Logger.Prefix()), ":")
+}
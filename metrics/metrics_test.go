@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	stdlog "log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+
+	// Test the logger meets the interface
+	var _ log.Logger = new(Logger)
+
+	buf := &bytes.Buffer{}
+	reg := NewRegistry("myapp")
+	lgr := New(std.New(stdlog.New(buf, "db: ", 0)), Config{
+		Registry: reg,
+		Sampling: map[levels.Type]uint64{levels.Warn: 3, levels.Panic: 3},
+	})
+
+	lgr.Debug("test debug")
+	lgr.Info("test info")
+	lgr.Errorf("Hello %s", "World")
+	lgr.Error("foo bar", log.Map{"baz": "qux"})
+	assert.Contains(t, buf.String(), `[INFO]  test info`)
+	assert.Contains(t, buf.String(), `[ERROR] Hello World`)
+	assert.NotContains(t, buf.String(), `test debug`)
+	buf.Reset()
+
+	assert.Equal(t, uint64(1), reg.Value("dropped_total", "debug", "db"))
+	assert.Equal(t, uint64(1), reg.Value("entries_total", "info", "db"))
+	assert.Equal(t, uint64(2), reg.Value("entries_total", "error", "db"))
+
+	// Test sampling keeps 1 out of 3 entries
+	for i := 0; i < 7; i++ {
+		lgr.Warnf("warn %d", i)
+	}
+	assert.Equal(t, "db: [WARN]  warn 0\ndb: [WARN]  warn 3\ndb: [WARN]  warn 6\n", buf.String())
+	assert.Equal(t, uint64(3), reg.Value("entries_total", "warning", "db"))
+	assert.Equal(t, uint64(4), reg.Value("sampled_total", "warning", "db"))
+
+	// Test panics are never sampled
+	for i := 0; i < 2; i++ {
+		assert.Panics(t, func() { lgr.Panic("test panic") })
+	}
+	assert.Equal(t, uint64(2), reg.Value("entries_total", "panic", "db"))
+
+	// Test the prefix label follows the wrapped logger
+	lgr.Prefix("cache: ")
+	lgr.Info("test info")
+	assert.Equal(t, uint64(1), reg.Value("entries_total", "info", "cache"))
+
+	// Test named loggers share the registry and the sampling counts
+	buf.Reset()
+	sub := lgr.Named("sub").WithLevel(levels.Debug)
+	sub.Debug("test debug")
+	sub.Warn("warn 7")
+	lgr.Warn("warn 8")
+	assert.Equal(t, "cache.sub: [DEBUG] test debug\n", buf.String())
+	assert.Equal(t, uint64(1), reg.Value("entries_total", "debug", "cache.sub"))
+	assert.Equal(t, uint64(1), reg.Value("sampled_total", "warning", "cache"))
+	assert.Equal(t, uint64(1), reg.Value("sampled_total", "warning", "cache.sub"))
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry("log")
+	lgr := New(std.New(stdlog.New(ioutil.Discard, "", 0)), Config{Registry: reg})
+	lgr.Error("foo")
+	lgr.Prefix(`a"b`)
+	lgr.Trace("bar")
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Equal(t, 1, strings.Count(body, "# TYPE log_dropped_total counter\n"))
+	assert.Equal(t, 1, strings.Count(body, "# TYPE log_entries_total counter\n"))
+	assert.Contains(t, body, `log_dropped_total{level="trace",prefix="a\"b"} 1`+"\n")
+	assert.Contains(t, body, `log_entries_total{level="error",prefix=""} 1`+"\n")
+	assert.Contains(t, body, `log_entries_total{level="info",prefix="a\"b"} 0`+"\n") // series exist from the start
+	assert.NotContains(t, body, `log_entries_total{level="info",prefix=""}`)         // unused series are moved
+	assert.NotContains(t, body, `sampled_total`)
+}
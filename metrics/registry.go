@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/roninzo/log/levels"
+)
+
+// Metric names, without namespace.
+const (
+	metricEntries = "entries_total"
+	metricDropped = "dropped_total"
+	metricSampled = "sampled_total"
+)
+
+// Help texts of the metrics.
+var help = map[string]string{
+	metricEntries: "Number of log entries written, by level and prefix.",
+	metricDropped: "Number of log entries discarded for being below the logger level, by level and prefix.",
+	metricSampled: "Number of log entries discarded by sampling, by level and prefix.",
+}
+
+// series identifies a counter.
+type series struct {
+	metric string
+	level  string
+	prefix string
+}
+
+// Registry holds the counters of one or more metrics loggers, and exposes
+// them in the Prometheus text exposition format.
+type Registry struct {
+	namespace string
+	mu        sync.RWMutex
+	counters  map[series]*uint64
+}
+
+// DefaultRegistry is the registry used by loggers without a configured one.
+var DefaultRegistry = NewRegistry("log")
+
+// NewRegistry creates a registry. The namespace prefixes metric names, i.e.
+// "log" gives log_entries_total.
+func NewRegistry(namespace string) *Registry {
+	return &Registry{
+		namespace: namespace,
+		counters:  map[series]*uint64{},
+	}
+}
+
+// counter returns the counter of a series, creating it when missing.
+func (r *Registry) counter(s series) *uint64 {
+	r.mu.RLock()
+	c, ok := r.counters[s]
+	r.mu.RUnlock()
+	if ok {
+		return c
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok = r.counters[s]; !ok {
+		c = new(uint64)
+		r.counters[s] = c
+	}
+	return c
+}
+
+// register creates the entries series of a prefix, so that they exist from
+// the start.
+func (r *Registry) register(prefix string) {
+	for level := levels.Trace; level < levels.Silent; level++ {
+		r.counter(series{metricEntries, level.String(), prefix})
+	}
+}
+
+// unregister removes the entries series of a prefix which are still unused.
+func (r *Registry) unregister(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for level := levels.Trace; level < levels.Silent; level++ {
+		s := series{metricEntries, level.String(), prefix}
+		if c, ok := r.counters[s]; ok && atomic.LoadUint64(c) == 0 {
+			delete(r.counters, s)
+		}
+	}
+}
+
+func (r *Registry) inc(s series) {
+	atomic.AddUint64(r.counter(s), 1)
+}
+
+// Value returns the current value of a counter, i.e.
+// Value("entries_total", levels.Error.String(), "db").
+func (r *Registry) Value(metric, level, prefix string) uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.counters[series{metric, level, prefix}]; ok {
+		return atomic.LoadUint64(c)
+	}
+	return 0
+}
+
+// WriteTo writes all the counters in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	all := make([]series, 0, len(r.counters))
+	for s := range r.counters {
+		all = append(all, s)
+	}
+	r.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool {
+		switch {
+		case all[i].metric != all[j].metric:
+			return all[i].metric < all[j].metric
+		case all[i].prefix != all[j].prefix:
+			return all[i].prefix < all[j].prefix
+		default:
+			return all[i].level < all[j].level
+		}
+	})
+	b := &strings.Builder{}
+	for i, s := range all {
+		name := s.metric
+		if r.namespace != "" {
+			name = r.namespace + "_" + name
+		}
+		if i == 0 || all[i-1].metric != s.metric {
+			fmt.Fprintf(b, "# HELP %s %s\n", name, help[s.metric])
+			fmt.Fprintf(b, "# TYPE %s counter\n", name)
+		}
+		fmt.Fprintf(b, "%s{level=\"%s\",prefix=\"%s\"} %d\n",
+			name, escape(s.level), escape(s.prefix), r.Value(s.metric, s.level, s.prefix))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler returns an http.Handler serving the counters in the Prometheus text
+// exposition format, i.e. to be scraped on /metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = r.WriteTo(w)
+	})
+}
+
+// Handler returns an http.Handler serving the counters of the default
+// registry.
+func Handler() http.Handler {
+	return DefaultRegistry.Handler()
+}
+
+// escaper escapes the label values, as per the text exposition format.
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escape escapes a label value, as per the text exposition format.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/roninzo/log"
+)
+
+// Redacted replaces the values of the body fields which are not allowed.
+const Redacted = log.Redacted
+
+// AllBodyFields is the BodyFields allow-list logging all the bodies as is.
+var AllBodyFields = []string{"*"}
+
+// SensitiveBodyFields are the fields of JSON and form bodies which values are
+// replaced with Redacted when BodyFields is nil. They are matched regardless
+// of case.
+var SensitiveBodyFields = []string{
+	"password", "passwd", "pwd", "secret", "client_secret",
+	"token", "access_token", "refresh_token", "id_token",
+	"api_key", "apikey", "authorization", "cookie",
+	"credit_card", "card_number", "cvv", "ssn",
+}
+
+// body returns the loggable form of a request or response body: its size
+// when not textual, or else its redacted and truncated text.
+func (l *Logger) body(contentType string, b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !textual(mediaType) || !utf8.Valid(b) {
+		return fmt.Sprintf("(%d bytes)", len(b))
+	}
+	s := l.redact(mediaType, string(b))
+	if len(s) > l.MaxBodySize {
+		i := l.MaxBodySize
+		for i > 0 && !utf8.RuneStart(s[i]) { // do not split runes
+			i--
+		}
+		s = s[:i] + "..."
+	}
+	return s
+}
+
+// textual reports whether a media type holds text.
+func textual(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json",
+		"application/xml",
+		"application/javascript",
+		"application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+// redact replaces the values of the fields which are not in BodyFields, or
+// of the SensitiveBodyFields when BodyFields is nil. Other textual bodies are
+// redacted altogether, unless BodyFields is nil.
+func (l *Logger) redact(mediaType, s string) string {
+	r := redactor{allow: l.BodyFields != nil, fields: map[string]bool{}}
+	fields := l.BodyFields
+	if !r.allow {
+		fields = SensitiveBodyFields
+	}
+	for _, field := range fields {
+		if !r.allow {
+			field = strings.ToLower(field)
+		}
+		r.fields[field] = true
+	}
+	if r.fields["*"] {
+		return s
+	}
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(s)
+		if err != nil {
+			return r.other(s)
+		}
+		for key := range values {
+			if r.redacted(key) {
+				values[key] = []string{Redacted}
+			}
+		}
+		return values.Encode()
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return r.other(s)
+		}
+		b, err := json.Marshal(r.json(v))
+		if err != nil {
+			return Redacted
+		}
+		return string(b)
+	}
+	return r.other(s)
+}
+
+// redactor redacts the body fields which are not in an allow-list, or which
+// are in a deny-list of lower case names.
+type redactor struct {
+	allow  bool
+	fields map[string]bool
+}
+
+// redacted reports whether the value of a field is redacted.
+func (r redactor) redacted(key string) bool {
+	if r.allow {
+		return !r.fields[key]
+	}
+	return r.fields[strings.ToLower(key)]
+}
+
+// other redacts a body which fields cannot be told apart.
+func (r redactor) other(s string) string {
+	if r.allow {
+		return Redacted
+	}
+	return s
+}
+
+// json replaces the values of the redacted object fields with Redacted, at any
+// depth. With an allow-list, other scalar values are replaced too.
+func (r redactor) json(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			switch {
+			case r.allow && !r.redacted(key): // kept as is
+			case !r.allow && r.redacted(key):
+				v[key] = Redacted
+			default:
+				v[key] = r.json(val)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = r.json(val)
+		}
+		return v
+	}
+	if r.allow {
+		return Redacted
+	}
+	return v
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/roninzo/log/levels"
 )
 
 // Config defines the config for middleware.
@@ -16,10 +17,21 @@ type Config struct {
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 
-	// // Format defines the logging tags
-	// //
-	// // Optional. Default: [${time}] ${status} - ${latency} ${method} ${path}\n
-	// Format string
+	// Format defines the logging tags, i.e. "${status} ${reqHeader:X-Key}".
+	// Besides the package Tags, parametrised tags read request headers (reqHeader:),
+	// response headers (respHeader:), locals (locals:), query parameters
+	// (query:), form values (form:) and cookies (cookie:). When set, the tags
+	// of Format are logged as fields instead of the ones selected by Fields.
+	//
+	// Optional. Default: "", or DefaultFormat with TextOutput
+	Format string
+
+	// TextOutput writes Format as plain text into Output, instead of logging
+	// fields through the logger. Color tags, i.e. "${red}", are only rendered
+	// in text output.
+	//
+	// Optional. Default: false
+	TextOutput bool
 
 	// TimeFormat https://programming.guide/go/format-parse-string-time-date-example.html
 	//
@@ -41,11 +53,77 @@ type Config struct {
 	// Default: os.Stderr
 	Output io.Writer
 
-	// Flag defines what to log.
+	// Fields defines what to log: the fields of the flags which are set, as
+	// with the flags of the standard log package.
+	//
+	// Optional. Default: LstdFlags (Lpid | LIP | Luid | Lstatus | LbytesSent | Llatency | Lmethod | Lpath)
+	Fields int
+
+	// Flag defines what NOT to log: the fields of the flags which are not set
+	// are logged. It is only used when Fields is not set.
 	//
-	// Default: LstdFlags (Lpid | LIP | Luid | Lstatus | LbytesSent | Llatency | Lmethod | Lpath)
+	// Deprecated: use Fields, i.e. Fields: LallFlags &^ Flag.
 	Flag int
 
+	// Include adds fields to the ones of Fields (or Flag), i.e. Lroute | Lerror.
+	//
+	// Optional. Default: 0
+	Include int
+
+	// Exclude removes fields from the ones of Fields (or Flag) and Include,
+	// i.e. Lpid. Exclude: LallFlags logs no fields besides the captured ones.
+	//
+	// Optional. Default: 0
+	Exclude int
+
+	// LevelFunc returns the level of the access log entry of a request, given
+	// the error returned by the handler chain, if any.
+	//
+	// Optional. Default: nil, for Error on 5xx responses or errors, Warn on
+	// 4xx responses or slow requests, and Info otherwise
+	LevelFunc func(c *fiber.Ctx, err error) levels.Type
+
+	// SlowThreshold is the latency above which a request is logged at Warn,
+	// with a "slow" field. A zero value disables it.
+	//
+	// Optional. Default: 0
+	SlowThreshold time.Duration
+
+	// RequestHeaders, ResponseHeaders and Cookies are logged as fields, named
+	// after their Format tags, i.e. "reqHeader:X-Request-ID".
+	//
+	// Optional. Default: nil
+	RequestHeaders  []string
+	ResponseHeaders []string
+	Cookies         []string
+
+	// MaxBodySize is the number of bytes of the request and response bodies
+	// logged by Lbody and LresBody; longer bodies are truncated. Bodies which
+	// content type is not textual are replaced with their size.
+	//
+	// Optional. Default: 1024
+	MaxBodySize int
+
+	// BodyFields is the allow-list of the fields of JSON and form bodies which
+	// are logged as is; the values of other fields are replaced with Redacted,
+	// as are other textual bodies. A nil list only redacts the values of the
+	// SensitiveBodyFields, while the AllBodyFields list disables redaction.
+	//
+	// Optional. Default: nil, the SensitiveBodyFields are redacted
+	BodyFields []string
+
+	// Naming is the naming convention of the field keys.
+	//
+	// Optional. Default: NamingDefault
+	Naming Naming
+
+	// Tags overrides the field keys of some flags, i.e.
+	// map[int]string{Lstatus: "code"}. It does not change the tag names in
+	// Format.
+	//
+	// Optional. Default: nil
+	Tags map[int]string
+
 	// Context Key for User ID
 	//
 	// Default: "userid"
@@ -61,9 +139,11 @@ type Config struct {
 	timeZoneLocation *time.Location
 }
 
+// DefaultFormat is the Format of text output when none is given.
+const DefaultFormat = "[${time}] ${status} - ${latency} ${method} ${path}\n"
+
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	// Format:       "[${time}] ${status} - ${latency} ${method} ${path}\n",
 	// enableColors: true,
 	Next:          nil,
 	TimeFormat:    "15:04:05",
@@ -72,7 +152,8 @@ var ConfigDefault = Config{
 	ContextKeyRID: requestid.ConfigDefault.ContextKey, // "requestid",
 	TimeInterval:  500 * time.Millisecond,
 	Output:        os.Stderr,
-	Flag:          LstdFlags,
+	Fields:        LstdFlags,
+	MaxBodySize:   1024,
 }
 
 // Helper function to set default values
@@ -91,9 +172,9 @@ func configDefault(config ...Config) Config {
 	// }
 
 	// Set default values
-	// if cfg.Format == "" {
-	// 	cfg.Format = ConfigDefault.Format
-	// }
+	if cfg.TextOutput && cfg.Format == "" {
+		cfg.Format = DefaultFormat
+	}
 	if cfg.Next == nil {
 		cfg.Next = ConfigDefault.Next
 	}
@@ -106,14 +187,17 @@ func configDefault(config ...Config) Config {
 	if int(cfg.TimeInterval) <= 0 {
 		cfg.TimeInterval = ConfigDefault.TimeInterval
 	}
-	if cfg.Flag == 0 {
-		cfg.Flag = ConfigDefault.Flag
+	if cfg.Fields == 0 && cfg.Flag == 0 {
+		cfg.Fields = ConfigDefault.Fields
 	}
 	if cfg.ContextKeyUID == "" {
-		cfg.TimeFormat = ConfigDefault.ContextKeyUID
+		cfg.ContextKeyUID = ConfigDefault.ContextKeyUID
 	}
 	if cfg.ContextKeyRID == "" {
-		cfg.TimeFormat = ConfigDefault.ContextKeyRID
+		cfg.ContextKeyRID = ConfigDefault.ContextKeyRID
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = ConfigDefault.MaxBodySize
 	}
 	if cfg.Output == nil {
 		cfg.Output = ConfigDefault.Output
@@ -134,3 +218,12 @@ func configDefault(config ...Config) Config {
 // 	}
 // 	return true
 // }
+
+// flags returns the flags of the fields to log.
+func (cfg Config) flags() int {
+	flag := cfg.Fields
+	if flag == 0 { // deprecated Flag
+		flag = LallFlags &^ cfg.Flag
+	}
+	return (flag | cfg.Include) &^ cfg.Exclude
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roninzo/log"
+)
+
+// localsKey is the key of the request scope in the locals of a request.
+const localsKey = "github.com/roninzo/log/middlew/fiber/logger"
+
+// scope is the request scoped state of the middleware.
+type scope struct {
+	l      *Logger
+	mu     sync.Mutex // guards fields
+	fields log.Map    // fields added by handlers
+}
+
+// FromCtx returns a logger for handlers behind the middleware, carrying the
+// request id, user id, method and route of the request, along with the fields
+// added with AddFields. It returns log.Current when the middleware did not
+// handle the request.
+func FromCtx(c *fiber.Ctx) log.Logger {
+	s, ok := c.Locals(localsKey).(*scope)
+	if !ok {
+		return log.Current
+	}
+	fields := log.Map{
+		s.l.keys[Lmethod]: c.Method(),
+		s.l.keys[Lroute]:  c.Route().Path,
+	}
+	if rid := c.Locals(s.l.ContextKeyRID); rid != nil {
+		fields[s.l.keys[Lrid]] = rid
+	}
+	if uid := c.Locals(s.l.ContextKeyUID); uid != nil {
+		fields[s.l.keys[Luid]] = uid
+	}
+	return log.With(s.l.Logger, log.Merge(fields, s.added()))
+}
+
+// AddFields adds fields to the access log entry of a request, as well as to
+// the loggers returned by FromCtx afterwards. It does nothing when the
+// middleware did not handle the request.
+func AddFields(c *fiber.Ctx, fields log.Map) {
+	s, ok := c.Locals(localsKey).(*scope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, val := range fields {
+		s.fields[key] = val
+	}
+}
+
+// added returns a copy of the fields added by handlers.
+func (s *scope) added() log.Map {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return log.Merge(s.fields)
+}
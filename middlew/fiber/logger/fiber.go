@@ -4,28 +4,46 @@
 package logger
 
 import (
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
 )
 
 // Fiber logger via composition.
 type Logger struct {
 	log.Logger
 	Config
+
+	pid     string
+	flag    int            // flags of the fields to log
+	keys    map[int]string // field keys of the flags
+	parts   []part         // compiled Format
+	capture []part         // captured headers and cookies
+	mu      sync.Mutex     // guards Output
+}
+
+// request holds the values of a request which are not available from its
+// context.
+type request struct {
+	start time.Time
+	stop  time.Time
+	err   error
 }
 
-// Fiber logging handler.
+// Fiber logging handler. New panics when Format holds an invalid tag.
 func New(lgr log.Logger, config ...Config) func(c *fiber.Ctx) error {
 	// Set default config
 	cfg := configDefault(config...)
 
 	// Current logger.
-	l := &Logger{lgr, cfg}
+	l := &Logger{Logger: lgr, Config: cfg}
 
 	// Get timezone location
 	tz, err := time.LoadLocation(l.TimeZone)
@@ -35,12 +53,29 @@ func New(lgr log.Logger, config ...Config) func(c *fiber.Ctx) error {
 		l.timeZoneLocation = tz
 	}
 
-	// Set PID once
-	pid := strconv.Itoa(os.Getpid())
+	// Set PID, flags and field keys once
+	l.pid = strconv.Itoa(os.Getpid())
+	l.flag = l.flags()
+	l.keys = l.fieldKeys()
 
-	// Tag overrides from Config.
-	Tags[Lrid] = l.ContextKeyRID
-	Tags[Luid] = l.ContextKeyUID
+	// Compile format once
+	if l.Format != "" {
+		l.parts, err = compile(l.Format, Tags)
+		if err != nil {
+			panic("logger: invalid format: " + err.Error())
+		}
+	}
+
+	// Set captured headers and cookies once
+	for kind, names := range map[string][]string{
+		TagReqHeader:  l.RequestHeaders,
+		TagRespHeader: l.ResponseHeaders,
+		TagCookie:     l.Cookies,
+	} {
+		for _, name := range names {
+			l.capture = append(l.capture, part{name: kind + name, kind: kind, param: name})
+		}
+	}
 
 	// Set variables
 	var (
@@ -61,140 +96,204 @@ func New(lgr log.Logger, config ...Config) func(c *fiber.Ctx) error {
 			errHandler = c.App().Config().ErrorHandler
 		})
 
-		var start, stop time.Time
-
 		// Set latency start time
-		if l.Flag&Llatency == 0 {
-			start = time.Now()
-		}
+		r := &request{start: time.Now()}
+
+		// Set request scope, for FromCtx and AddFields
+		s := &scope{l: l, fields: log.Map{}}
+		c.Locals(localsKey, s)
 
 		// Handle request, store err for logging
-		chainErr := c.Next()
+		r.err = c.Next()
 
 		// Manually call error handler
-		if chainErr != nil {
-			if err := errHandler(c, chainErr); err != nil {
+		if r.err != nil {
+			if err := errHandler(c, r.err); err != nil {
 				_ = c.SendStatus(fiber.StatusInternalServerError)
 			}
 		}
 
 		// Set latency stop time
-		if l.Flag&Llatency == 0 {
-			stop = time.Now()
+		r.stop = time.Now()
+
+		if l.TextOutput {
+			l.write(c, r)
+			return nil
+		}
+		fields := log.Merge(l.fields(c, r), s.added())
+		if l.slow(r) {
+			fields["slow"] = true
 		}
+		l.log(l.level(c, r), log.MesgFiberLogger, fields)
+		return nil
+	}
+}
 
-		// This is our custom list of arguments to log
-		fields := log.Map{}
-		{
-			var key string
-
-			// LstdFlags
-			{
-				if l.Flag&Lpid == 0 {
-					key = Tags[Lpid]
-					fields[key] = pid
-				}
-				if l.Flag&LIP == 0 {
-					key = Tags[LIP]
-					fields[key] = c.IP()
-				}
-				if l.Flag&Luid == 0 {
-					key = Tags[Luid]
-					fields[key] = c.Locals(key)
-				}
-				if l.Flag&Lstatus == 0 {
-					key = Tags[Lstatus]
-					fields[key] = c.Response().StatusCode()
-				}
-				if l.Flag&LbytesSent == 0 {
-					key = Tags[LbytesSent]
-					fields[key] = len(c.Response().Body())
-				}
-				if l.Flag&Llatency == 0 {
-					key = Tags[Llatency]
-					fields[key] = stop.Sub(start).String()
-				}
-				if l.Flag&Lmethod == 0 {
-					key = Tags[Lmethod]
-					fields[key] = c.Method()
-				}
-				if l.Flag&Lpath == 0 {
-					key = Tags[Lpath]
-					fields[key] = c.Path()
-				}
-			}
+// slow reports whether a request took longer than SlowThreshold.
+func (l *Logger) slow(r *request) bool {
+	return l.SlowThreshold > 0 && r.stop.Sub(r.start) > l.SlowThreshold
+}
+
+// level returns the level of the access log entry of a request.
+func (l *Logger) level(c *fiber.Ctx, r *request) levels.Type {
+	if l.LevelFunc != nil {
+		return l.LevelFunc(c, r.err)
+	}
+	status := c.Response().StatusCode()
+	switch {
+	case r.err != nil || status >= fiber.StatusInternalServerError:
+		return levels.Error
+	case status >= fiber.StatusBadRequest || l.slow(r):
+		return levels.Warn
+	default:
+		return levels.Info
+	}
+}
+
+// log logs at the given level.
+func (l *Logger) log(level levels.Type, msg ...interface{}) {
+	switch level {
+	case levels.Trace:
+		l.Trace(msg...)
+	case levels.Debug:
+		l.Debug(msg...)
+	case levels.Info:
+		l.Info(msg...)
+	case levels.Warn:
+		l.Warn(msg...)
+	case levels.Error:
+		l.Error(msg...)
+	case levels.Panic:
+		l.Panic(msg...)
+	case levels.Fatal:
+		l.Fatal(msg...)
+	}
+}
 
-			// Non-LstdFlags
-			{
-				if l.Flag&Ltime == 0 {
-					key = Tags[Ltime]
-					fields[key] = time.Now().In(l.timeZoneLocation).Format(l.TimeFormat)
-				}
-				if l.Flag&Lreferer == 0 {
-					key = Tags[Lreferer]
-					fields[key] = c.Get(fiber.HeaderReferer)
-				}
-				if l.Flag&Lprotocol == 0 {
-					key = Tags[Lprotocol]
-					fields[key] = c.Protocol()
-				}
-				if l.Flag&Lport == 0 {
-					key = Tags[Lport]
-					fields[key] = c.Port()
-				}
-				if l.Flag&LIPs == 0 {
-					key = Tags[LIPs]
-					fields[key] = c.Get(fiber.HeaderXForwardedFor)
-				}
-				if l.Flag&Lhost == 0 {
-					key = Tags[Lhost]
-					fields[key] = c.Hostname()
-				}
-				if l.Flag&LURL == 0 {
-					key = Tags[LURL]
-					fields[key] = c.OriginalURL()
-				}
-				if l.Flag&LUA == 0 {
-					key = Tags[LUA]
-					fields[key] = c.Get(fiber.HeaderUserAgent)
-				}
-				if l.Flag&LresBody == 0 {
-					key = Tags[LresBody]
-					fields[key] = c.Response().Body()
-				}
-				if l.Flag&LqueryStringParams == 0 {
-					key = Tags[LqueryStringParams]
-					fields[key] = c.Request().URI().QueryArgs().String()
-				}
-				if l.Flag&Lbody == 0 {
-					key = Tags[Lbody]
-					fields[key] = c.Body()
-				}
-				if l.Flag&LbytesReceived == 0 {
-					key = Tags[LbytesReceived]
-					fields[key] = len(c.Request().Body())
-				}
-				if l.Flag&Lroute == 0 {
-					key = Tags[Lroute]
-					fields[key] = c.Route().Path
-				}
-				if l.Flag&Lerror == 0 {
-					key = Tags[Lerror]
-					fields[key] = "-"
-
-					// Error if exist
-					if chainErr != nil {
-						fields[key] = chainErr.Error()
-					}
-				}
-				if l.Flag&Lrid == 0 {
-					key = Tags[Lrid]
-					fields[key] = c.Locals(key)
-				}
+// fields returns the fields of the access log entry: the captured headers and
+// cookies, and the tags of Format when set, or else the tags selected by
+// Fields, Include and Exclude.
+func (l *Logger) fields(c *fiber.Ctx, r *request) log.Map {
+	fields := log.Map{}
+	for _, p := range l.capture {
+		fields[p.name] = l.value(c, r, p)
+	}
+	if l.parts != nil {
+		for _, p := range l.parts {
+			if p.flag != 0 {
+				fields[l.keys[p.flag]] = l.field(c, r, p)
+			} else if p.name != "" {
+				fields[p.name] = l.value(c, r, p)
 			}
 		}
+		return fields
+	}
+	for flag, key := range l.keys {
+		if l.flag&flag != 0 {
+			fields[key] = l.field(c, r, part{flag: flag})
+		}
+	}
+	return fields
+}
 
-		l.Info(log.MesgFiberLogger, fields)
-		return nil
+// write renders Format into Output.
+func (l *Logger) write(c *fiber.Ctx, r *request) {
+	b := &strings.Builder{}
+	for _, p := range l.parts {
+		if p.name == "" {
+			b.WriteString(p.text)
+			continue
+		}
+		b.WriteString(toString(l.value(c, r, p)))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.Output.Write([]byte(b.String()))
+}
+
+// field returns the value of a flag for a request, as a field: the latency is
+// a number in the unit of the naming convention, if any.
+func (l *Logger) field(c *fiber.Ctx, r *request, p part) interface{} {
+	if p.flag == Llatency {
+		switch l.Naming {
+		case NamingECS: // event.duration, in nanoseconds
+			return r.stop.Sub(r.start).Nanoseconds()
+		case NamingOTel: // http.server.request.duration, in seconds
+			return r.stop.Sub(r.start).Seconds()
+		}
+	}
+	return l.value(c, r, p)
+}
+
+// value returns the value of a tag for a request.
+func (l *Logger) value(c *fiber.Ctx, r *request, p part) interface{} {
+	switch p.kind {
+	case TagReqHeader:
+		return c.Get(p.param)
+	case TagRespHeader:
+		return c.GetRespHeader(p.param)
+	case TagLocals:
+		return c.Locals(p.param)
+	case TagQuery:
+		return c.Query(p.param)
+	case TagForm:
+		return c.FormValue(p.param)
+	case TagCookie:
+		return c.Cookies(p.param)
+	}
+	switch p.flag {
+	case Lpid:
+		return l.pid
+	case LIP:
+		return c.IP()
+	case Luid:
+		return c.Locals(l.ContextKeyUID)
+	case Lstatus:
+		return c.Response().StatusCode()
+	case LbytesSent:
+		return len(c.Response().Body())
+	case Llatency:
+		return r.stop.Sub(r.start).String()
+	case Lmethod:
+		return c.Method()
+	case Lpath:
+		return c.Path()
+	case Ltime:
+		return time.Now().In(l.timeZoneLocation).Format(l.TimeFormat)
+	case Lreferer:
+		return c.Get(fiber.HeaderReferer)
+	case Lprotocol:
+		return c.Protocol()
+	case Lport:
+		if addr, ok := c.Context().RemoteAddr().(*net.TCPAddr); ok {
+			return strconv.Itoa(addr.Port)
+		}
+		return ""
+	case LIPs:
+		return c.Get(fiber.HeaderXForwardedFor)
+	case Lhost:
+		return c.Hostname()
+	case LURL:
+		return c.OriginalURL()
+	case LUA:
+		return c.Get(fiber.HeaderUserAgent)
+	case LresBody:
+		return l.body(string(c.Response().Header.ContentType()), c.Response().Body())
+	case LqueryStringParams:
+		return c.Request().URI().QueryArgs().String()
+	case Lbody:
+		return l.body(c.Get(fiber.HeaderContentType), c.Body())
+	case LbytesReceived:
+		return len(c.Request().Body())
+	case Lroute:
+		return c.Route().Path
+	case Lerror:
+		if r.err != nil {
+			return r.err.Error()
+		}
+		return "-"
+	case Lrid:
+		return c.Locals(l.ContextKeyRID)
 	}
+	return nil
 }
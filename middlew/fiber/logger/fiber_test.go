@@ -0,0 +1,373 @@
+package logger
+
+import (
+	"bytes"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+)
+
+// newApp returns an app logging through the middleware into buf.
+func newApp(buf *bytes.Buffer, cfg Config) *fiber.App {
+	app := fiber.New()
+	app.Use(New(std.New(stdlog.New(buf, "", 0)), cfg))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		c.Set("X-Resp", "pong")
+		c.Locals("tenant", "acme")
+		return c.SendString("hello")
+	})
+	return app
+}
+
+func TestFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := newApp(buf, Config{
+		Format: "${status} ${method} ${reqHeader:X-Key} ${respHeader:X-Resp} ${locals:tenant} ${query:q} ${cookie:sid}",
+	})
+	req := httptest.NewRequest("GET", "/users/1?q=search", nil)
+	req.Header.Set("X-Key", "ping")
+	req.Header.Set("Cookie", "sid=abc")
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, log.MesgFiberLogger)
+	assert.Contains(t, out, "[status=")
+	assert.Contains(t, out, "[method=GET]")
+	assert.Contains(t, out, "[reqHeader:X-Key=ping]")
+	assert.Contains(t, out, "[respHeader:X-Resp=pong]")
+	assert.Contains(t, out, "[locals:tenant=acme]")
+	assert.Contains(t, out, "[query:q=search]")
+	assert.Contains(t, out, "[cookie:sid=abc]")
+	assert.NotContains(t, out, "[pid=") // Format replaces Fields
+}
+
+func TestTextOutput(t *testing.T) {
+	buf, out := &bytes.Buffer{}, &bytes.Buffer{}
+	app := newApp(buf, Config{
+		Format:     "${red}${status}${reset} ${method} ${path} ${form:name}\n",
+		TextOutput: true,
+		Output:     out,
+	})
+	req := httptest.NewRequest("POST", "/users/1", bytes.NewBufferString("name=john"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.Post("/users/:id", func(c *fiber.Ctx) error { return nil })
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "\u001b[91m200\u001b[0m POST /users/1 john\n", out.String())
+	assert.Empty(t, buf.String())
+
+	// Test the default format
+	out.Reset()
+	app = newApp(buf, Config{TextOutput: true, Output: out})
+	_, err = app.Test(httptest.NewRequest("GET", "/users/1", nil))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\[\d\d:\d\d:\d\d\] 200 - \S+ GET /users/1\n$`, out.String())
+}
+
+func TestCompile(t *testing.T) {
+	parts, err := compile("a ${status} b ${reqHeader:X} ${cyan}", Tags)
+	assert.NoError(t, err)
+	assert.Equal(t, []part{
+		{text: "a "},
+		{name: "status", flag: Lstatus},
+		{text: " b "},
+		{name: "reqHeader:X", kind: TagReqHeader, param: "X"},
+		{text: " "},
+		{text: colors[TagCyan]},
+	}, parts)
+
+	for _, format := range []string{"${unknown}", "${status", "${reqHeader:}"} {
+		_, err = compile(format, Tags)
+		assert.Error(t, err, format)
+	}
+	assert.Panics(t, func() { New(nil, Config{Format: "${unknown}"}) })
+}
+
+func TestLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := newApp(buf, Config{Format: "${status}", SlowThreshold: 20 * time.Millisecond})
+	app.Get("/bad", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusBadRequest) })
+	app.Get("/fail", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusBadGateway) })
+	app.Get("/err", func(c *fiber.Ctx) error { return fiber.ErrNotFound })
+	app.Get("/slow", func(c *fiber.Ctx) error { time.Sleep(30 * time.Millisecond); return nil })
+
+	tests := []struct {
+		path  string
+		level levels.Type
+	}{
+		{"/users/1", levels.Info},
+		{"/bad", levels.Warn},
+		{"/fail", levels.Error},
+		{"/err", levels.Error},
+		{"/slow", levels.Warn},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		_, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), tt.level.Bracket(), tt.path)
+		if tt.path == "/slow" {
+			assert.Contains(t, buf.String(), "[slow=")
+		} else {
+			assert.NotContains(t, buf.String(), "[slow=", tt.path)
+		}
+	}
+
+	// Test a custom level function
+	buf.Reset()
+	app = newApp(buf, Config{Format: "${status}", LevelFunc: func(c *fiber.Ctx, err error) levels.Type { return levels.Debug }})
+	_, err := app.Test(httptest.NewRequest("GET", "/users/1", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String()) // below the Info level of the logger
+}
+
+func TestFromCtx(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "rid-1")
+		return c.Next()
+	})
+	app.Use(New(std.New(stdlog.New(buf, "", 0)), Config{Format: "${status}", ContextKeyUID: "userid", ContextKeyRID: "requestid"}))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		c.Locals("userid", "john")
+		AddFields(c, log.Map{"tenant": "acme"})
+		FromCtx(c).Info("in handler", log.Map{"foo": "bar"})
+		return nil
+	})
+	_, err := app.Test(httptest.NewRequest("GET", "/users/1", nil))
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	for _, field := range []string{"[requestid=rid-1]", "[userid=john]", "[method=GET]", "[route=/users/:id]", "[tenant=acme]", "[foo=bar]"} {
+		assert.Contains(t, lines[0], field)
+	}
+	assert.Contains(t, lines[1], log.MesgFiberLogger)
+	assert.Contains(t, lines[1], "[tenant=acme]")
+
+	// Test without the middleware
+	app = fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		AddFields(c, log.Map{"tenant": "acme"})
+		assert.Equal(t, log.Current, FromCtx(c))
+		return nil
+	})
+	_, err = app.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+}
+
+func TestCapture(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := newApp(buf, Config{
+		Format:          "${status} ${body} ${resBody}",
+		RequestHeaders:  []string{"X-Key"},
+		ResponseHeaders: []string{"X-Resp"},
+		Cookies:         []string{"sid"},
+		MaxBodySize:     5,
+	})
+	req := httptest.NewRequest("GET", "/users/1", bytes.NewBufferString("abcdefgh"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Key", "ping")
+	req.Header.Set("Cookie", "sid=abc")
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "[reqHeader:X-Key=ping]")
+	assert.Contains(t, out, "[respHeader:X-Resp=pong]")
+	assert.Contains(t, out, "[cookie:sid=abc]")
+	assert.Contains(t, out, "[body=abcde...]")
+	assert.Contains(t, out, "[resBody=hello]")
+}
+
+func TestBody(t *testing.T) {
+	l := &Logger{Config: configDefault(Config{MaxBodySize: 64})}
+	tests := []struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		{"", "", ""},
+		{"image/png", "\x89PNG", "(4 bytes)"},
+		{"text/plain; charset=utf-8", "hello", "hello"},
+		{"application/json", `{"a":1,"b":[{"Token":"x"}]}`, `{"a":1,"b":[{"Token":"[REDACTED]"}]}`},
+		{"application/x-www-form-urlencoded", "user=john&password=secret", "password=%5BREDACTED%5D&user=john"},
+		{"text/plain", strings.Repeat("é", 40), strings.Repeat("é", 32) + "..."},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, l.body(tt.contentType, []byte(tt.body)), tt.contentType)
+	}
+
+	// Test redaction
+	l.MaxBodySize = 1024
+	l.BodyFields = []string{"user", "meta"}
+	tests = []struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		{"application/json", `{"user":"john","password":"secret","meta":{"k":"v"},"tokens":["a",{"user":"x"}]}`,
+			`{"meta":{"k":"v"},"password":"[REDACTED]","tokens":["[REDACTED]",{"user":"x"}],"user":"john"}`},
+		{"application/problem+json", `"secret"`, `"[REDACTED]"`},
+		{"application/json", `{`, Redacted},
+		{"application/x-www-form-urlencoded", "user=john&password=secret", "password=%5BREDACTED%5D&user=john"},
+		{"text/plain", "secret", Redacted},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, l.body(tt.contentType, []byte(tt.body)), tt.body)
+	}
+
+	// Test redaction can be disabled
+	l.BodyFields = AllBodyFields
+	assert.Equal(t, `{"password":"secret"}`, l.body("application/json", []byte(`{"password":"secret"}`)))
+	assert.Equal(t, "secret", l.body("text/plain", []byte("secret")))
+}
+
+func TestFlag(t *testing.T) {
+	tests := []struct {
+		flag int
+		key  string
+		want string
+	}{
+		{Lpid, "pid", strconv.Itoa(os.Getpid())},
+		{Ltime, "time", ""},
+		{Lreferer, "referer", "http://example.com/"},
+		{Lprotocol, "protocol", "http"},
+		{Lport, "port", ""},
+		{LIP, "ip", "0.0.0.0"},
+		{LIPs, "ips", "10.0.0.1"},
+		{Lhost, "host", "example.com"},
+		{Lmethod, "method", "GET"},
+		{Lpath, "path", "/users/1"},
+		{LURL, "url", "http://example.com/users/1?q=search"},
+		{LUA, "ua", "test-agent"},
+		{Llatency, "latency", ""},
+		{Lstatus, "status", "%!s(int=200)"},
+		{LresBody, "resBody", "hello"},
+		{LqueryStringParams, "query_string_params", "q=search"},
+		{Lbody, "body", "ping"},
+		{LbytesSent, "bytes_sent", "%!s(int=5)"},
+		{LbytesReceived, "bytes_received", "%!s(int=4)"},
+		{Lroute, "route", "/users/:id"},
+		{Lerror, "error", "-"},
+		{Luid, "userid", "john"},
+		{Lrid, "requestid", "rid-1"},
+	}
+	assert.Len(t, tests, len(Tags), "all flags are tested")
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("requestid", "rid-1")
+				c.Locals("userid", "john")
+				return c.Next()
+			})
+			app.Use(New(std.New(stdlog.New(buf, "", 0)), Config{Fields: tt.flag}))
+			app.Get("/users/:id", func(c *fiber.Ctx) error { return c.SendString("hello") })
+
+			req := httptest.NewRequest("GET", "http://example.com/users/1?q=search", bytes.NewBufferString("ping"))
+			req.Header.Set("Content-Type", "text/plain")
+			req.Header.Set("Referer", "http://example.com/")
+			req.Header.Set("X-Forwarded-For", "10.0.0.1")
+			req.Header.Set("User-Agent", "test-agent")
+			_, err := app.Test(req)
+			assert.NoError(t, err)
+
+			out := strings.TrimSpace(buf.String())
+			assert.Equal(t, 1, strings.Count(out, " ["), "only the flag field is logged: %s", out)
+			if tt.want == "" {
+				assert.Contains(t, out, "["+tt.key+"=")
+			} else {
+				assert.Contains(t, out, "["+tt.key+"="+tt.want+"]")
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"default", Config{}, LstdFlags},
+		{"fields", Config{Fields: Lpid | Lroute}, Lpid | Lroute},
+		{"include", Config{Include: Lroute | Lerror}, LstdFlags | Lroute | Lerror},
+		{"exclude", Config{Exclude: Lpid | LIP}, LstdFlags &^ (Lpid | LIP)},
+		{"exclude all", Config{Include: Lroute, Exclude: LallFlags}, 0},
+		{"include and exclude", Config{Fields: Lpid, Include: Lroute | Lerror, Exclude: Lerror}, Lpid | Lroute},
+		{"deprecated flag", Config{Flag: LstdFlags}, LallFlags &^ LstdFlags},
+		{"deprecated flag and include", Config{Flag: LallFlags &^ Lpid, Include: Lroute}, Lpid | Lroute},
+		{"fields over flag", Config{Fields: Lpid, Flag: Lpid}, Lpid},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, configDefault(tt.cfg).flags(), tt.name)
+	}
+}
+
+func TestConfigDefault(t *testing.T) {
+	cfg := configDefault(Config{Fields: Lpid})
+	assert.Equal(t, ConfigDefault.TimeFormat, cfg.TimeFormat)
+	assert.Equal(t, ConfigDefault.ContextKeyUID, cfg.ContextKeyUID)
+	assert.Equal(t, ConfigDefault.ContextKeyRID, cfg.ContextKeyRID)
+	assert.Equal(t, Lpid, cfg.Fields)
+
+	cfg = configDefault(Config{TimeFormat: time.RFC3339, ContextKeyUID: "uid"})
+	assert.Equal(t, time.RFC3339, cfg.TimeFormat)
+	assert.Equal(t, "uid", cfg.ContextKeyUID)
+	assert.Equal(t, "requestid", cfg.ContextKeyRID)
+}
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want []string
+	}{
+		{Config{ContextKeyRID: "rid"}, []string{"[rid=rid-1]", "[bytes_sent=", "[status="}},
+		{Config{Naming: NamingSnakeCase}, []string{"[request_id=rid-1]", "[bytes_sent="}},
+		{Config{Naming: NamingCamelCase}, []string{"[requestId=rid-1]", "[bytesSent="}},
+		{Config{Naming: NamingECS}, []string{"[http.request.id=rid-1]", "[http.response.body.bytes=", "[http.response.status_code=", "[event.duration=%!s(int64="}},
+		{Config{Naming: NamingOTel}, []string{"[http.request.id=rid-1]", "[http.response.body.size=", "[http.response.status_code=", "[http.server.request.duration=%!s(float64="}},
+		{Config{Naming: NamingECS, Tags: map[int]string{Lstatus: "code"}}, []string{"[code=", "[http.request.method=GET]"}},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("rid", "rid-1")
+			c.Locals("requestid", "rid-1")
+			return c.Next()
+		})
+		tt.cfg.Include = Lrid
+		app.Use(New(std.New(stdlog.New(buf, "", 0)), tt.cfg))
+		app.Get("/", func(c *fiber.Ctx) error { return nil })
+		_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		assert.NoError(t, err)
+		for _, want := range tt.want {
+			assert.Contains(t, buf.String(), want)
+		}
+	}
+	assert.Equal(t, "requestid", Tags[Lrid], "package tags are not mutated")
+
+	// Test Format tag names do not follow the naming
+	parts, err := compile("${bytes_sent}", Tags)
+	assert.NoError(t, err)
+	l := &Logger{Config: Config{Naming: NamingCamelCase}}
+	l.keys = l.fieldKeys()
+	assert.Equal(t, "bytesSent", l.keys[parts[0].flag])
+}
@@ -2,22 +2,6 @@ package logger
 
 // Logger flags; borrowed heavily from:
 // https://github.com/gofiber/fiber/blob/master/middleware/logger/logger.go
-//
-// LreqHeader
-// LrespHeader
-// Llocals
-// Lquery
-// Lform
-// Lcookie
-// LBlack
-// LRed
-// LGreen
-// LYellow
-// LBlue
-// LMagenta
-// LCyan
-// LWhite
-// LReset
 const (
 	Lpid = 1 << iota
 	Ltime
@@ -43,25 +27,12 @@ const (
 	Luid // Custom
 	Lrid
 	LstdFlags = Lpid | LIP | Luid | Lstatus | LbytesSent | Llatency | Lmethod | Lpath
+	LallFlags = Lrid<<1 - 1
 )
 
-// Tags
-//
-// LreqHeader:         "reqHeader:",
-// LrespHeader:        "respHeader:",
-// Llocals:            "locals:",
-// Lquery:             "query:",
-// Lform:              "form:",
-// Lcookie:            "cookie:",
-// LBlack:             "black",
-// LRed:               "red",
-// LGreen:             "green",
-// LYellow:            "yellow",
-// LBlue:              "blue",
-// LMagenta:           "magenta",
-// LCyan:              "cyan",
-// LWhite:             "white",
-// LReset:             "reset",
+// Tags are the names of the flags as tags in Format, i.e. "${status}", as well
+// as their default field keys. See Config.Naming and Config.Tags to change the
+// field keys.
 var Tags = map[int]string{
 	Lpid:               "pid",
 	Ltime:              "time",
@@ -88,36 +59,29 @@ var Tags = map[int]string{
 	Lrid:               "requestid",
 }
 
-// locals/query/form/reqHeader/respHeader/etc.
-//
-// default:
-// 	// Check if we have a value tag i.e.: "reqHeader:x-key"
-// 	switch {
-// 	case strings.HasPrefix(tag, TagReqHeader):
-// 		return buf.WriteString(c.Get(tag[10:]))
-// 	case strings.HasPrefix(tag, TagHeader):
-// 		return buf.WriteString(c.Get(tag[7:]))
-// 	case strings.HasPrefix(tag, TagRespHeader):
-// 		return buf.WriteString(c.GetRespHeader(tag[11:]))
-// 	case strings.HasPrefix(tag, TagQuery):
-// 		return buf.WriteString(c.Query(tag[6:]))
-// 	case strings.HasPrefix(tag, TagForm):
-// 		return buf.WriteString(c.FormValue(tag[5:]))
-// 	case strings.HasPrefix(tag, TagCookie):
-// 		return buf.WriteString(c.Cookies(tag[7:]))
-// 	case strings.HasPrefix(tag, TagLocals):
-// 		switch v := c.Locals(tag[7:]).(type) {
-// 		case []byte:
-// 			return buf.Write(v)
-// 		case string:
-// 			return buf.WriteString(v)
-// 		case nil:
-// 			return 0, nil
-// 		default:
-// 			return buf.WriteString(fmt.Sprintf("%v", v))
-// 		}
-// 	}
-// }
+// Parametrised tags, usable in Format followed by their parameter, i.e.
+// "${reqHeader:X-Request-ID}".
+const (
+	TagReqHeader  = "reqHeader:"
+	TagRespHeader = "respHeader:"
+	TagLocals     = "locals:"
+	TagQuery      = "query:"
+	TagForm       = "form:"
+	TagCookie     = "cookie:"
+)
+
+// Color tags, usable in Format, only rendered with TextOutput.
+const (
+	TagBlack   = "black"
+	TagRed     = "red"
+	TagGreen   = "green"
+	TagYellow  = "yellow"
+	TagBlue    = "blue"
+	TagMagenta = "magenta"
+	TagCyan    = "cyan"
+	TagWhite   = "white"
+	TagReset   = "reset"
+)
 
 // // These flags define which text to prefix to each log entry generated by the Logger.
 // // Bits are or'ed together to control what's printed.
@@ -0,0 +1,138 @@
+package logger
+
+// Naming is a naming convention of the field keys.
+type Naming int
+
+// Naming conventions.
+const (
+	NamingDefault   Naming = iota // Tags, with the context keys for Luid and Lrid
+	NamingSnakeCase               // i.e. bytes_sent
+	NamingCamelCase               // i.e. bytesSent
+	NamingECS                     // Elastic Common Schema, i.e. http.response.body.bytes
+	NamingOTel                    // OpenTelemetry semantic conventions, i.e. http.response.body.size
+)
+
+// namings holds the field keys of the flags per naming convention.
+var namings = map[Naming]map[int]string{
+	NamingSnakeCase: {
+		Lpid:               "pid",
+		Ltime:              "time",
+		Lreferer:           "referer",
+		Lprotocol:          "protocol",
+		Lport:              "port",
+		LIP:                "ip",
+		LIPs:               "ips",
+		Lhost:              "host",
+		Lmethod:            "method",
+		Lpath:              "path",
+		LURL:               "url",
+		LUA:                "ua",
+		Llatency:           "latency",
+		Lstatus:            "status",
+		LresBody:           "res_body",
+		LqueryStringParams: "query_string_params",
+		Lbody:              "body",
+		LbytesSent:         "bytes_sent",
+		LbytesReceived:     "bytes_received",
+		Lroute:             "route",
+		Lerror:             "error",
+		Luid:               "user_id",
+		Lrid:               "request_id",
+	},
+	NamingCamelCase: {
+		Lpid:               "pid",
+		Ltime:              "time",
+		Lreferer:           "referer",
+		Lprotocol:          "protocol",
+		Lport:              "port",
+		LIP:                "ip",
+		LIPs:               "ips",
+		Lhost:              "host",
+		Lmethod:            "method",
+		Lpath:              "path",
+		LURL:               "url",
+		LUA:                "ua",
+		Llatency:           "latency",
+		Lstatus:            "status",
+		LresBody:           "resBody",
+		LqueryStringParams: "queryStringParams",
+		Lbody:              "body",
+		LbytesSent:         "bytesSent",
+		LbytesReceived:     "bytesReceived",
+		Lroute:             "route",
+		Lerror:             "error",
+		Luid:               "userId",
+		Lrid:               "requestId",
+	},
+	NamingECS: {
+		Lpid:               "process.pid",
+		Ltime:              "@timestamp",
+		Lreferer:           "http.request.referrer",
+		Lprotocol:          "url.scheme",
+		Lport:              "client.port",
+		LIP:                "client.ip",
+		LIPs:               "network.forwarded_ip",
+		Lhost:              "url.domain",
+		Lmethod:            "http.request.method",
+		Lpath:              "url.path",
+		LURL:               "url.original",
+		LUA:                "user_agent.original",
+		Llatency:           "event.duration",
+		Lstatus:            "http.response.status_code",
+		LresBody:           "http.response.body.content",
+		LqueryStringParams: "url.query",
+		Lbody:              "http.request.body.content",
+		LbytesSent:         "http.response.body.bytes",
+		LbytesReceived:     "http.request.body.bytes",
+		Lroute:             "http.route",
+		Lerror:             "error.message",
+		Luid:               "user.id",
+		Lrid:               "http.request.id",
+	},
+	NamingOTel: {
+		Lpid:               "process.pid",
+		Ltime:              "time",
+		Lreferer:           "http.request.header.referer",
+		Lprotocol:          "url.scheme",
+		Lport:              "client.port",
+		LIP:                "client.address",
+		LIPs:               "http.request.header.x-forwarded-for",
+		Lhost:              "server.address",
+		Lmethod:            "http.request.method",
+		Lpath:              "url.path",
+		LURL:               "url.full",
+		LUA:                "user_agent.original",
+		Llatency:           "http.server.request.duration",
+		Lstatus:            "http.response.status_code",
+		LresBody:           "http.response.body",
+		LqueryStringParams: "url.query",
+		Lbody:              "http.request.body",
+		LbytesSent:         "http.response.body.size",
+		LbytesReceived:     "http.request.body.size",
+		Lroute:             "http.route",
+		Lerror:             "exception.message",
+		Luid:               "enduser.id",
+		Lrid:               "http.request.id",
+	},
+}
+
+// fieldKeys returns the field keys of the flags for a config: the ones of its
+// naming convention, overridden by its Tags.
+func (cfg Config) fieldKeys() map[int]string {
+	keys := make(map[int]string, len(Tags))
+	if naming, ok := namings[cfg.Naming]; ok {
+		for flag, key := range naming {
+			keys[flag] = key
+		}
+	} else {
+		for flag, key := range Tags {
+			keys[flag] = key
+		}
+		keys[Luid] = cfg.ContextKeyUID
+		keys[Lrid] = cfg.ContextKeyRID
+	}
+	for flag, key := range cfg.Tags {
+		keys[flag] = key
+	}
+	return keys
+}
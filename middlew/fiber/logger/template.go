@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escape sequences of the color tags.
+var colors = map[string]string{
+	TagBlack:   "\u001b[90m",
+	TagRed:     "\u001b[91m",
+	TagGreen:   "\u001b[92m",
+	TagYellow:  "\u001b[93m",
+	TagBlue:    "\u001b[94m",
+	TagMagenta: "\u001b[95m",
+	TagCyan:    "\u001b[96m",
+	TagWhite:   "\u001b[97m",
+	TagReset:   "\u001b[0m",
+}
+
+// Prefixes of the parametrised tags.
+var params = []string{
+	TagReqHeader,
+	TagRespHeader,
+	TagLocals,
+	TagQuery,
+	TagForm,
+	TagCookie,
+}
+
+// part is a compiled element of Format: literal text, a color or a tag.
+type part struct {
+	text  string // literal text or color escape sequence, when name is empty
+	name  string // name of the tag, used as field key, i.e. "reqHeader:X-Key"
+	flag  int    // flag of a standard tag, i.e. Lstatus
+	kind  string // prefix of a parametrised tag, i.e. TagReqHeader
+	param string // parameter of a parametrised tag, i.e. "X-Key"
+}
+
+// compile parses a Format into parts, so that requests do not pay for it.
+// Standard tags are looked up in tags, i.e. "${status}".
+func compile(format string, tags map[int]string) ([]part, error) {
+	names := make(map[string]int, len(tags))
+	for flag, name := range tags {
+		names[name] = flag
+	}
+	var parts []part
+	for format != "" {
+		i := strings.Index(format, "${")
+		if i < 0 {
+			parts = append(parts, part{text: format})
+			break
+		}
+		if i > 0 {
+			parts = append(parts, part{text: format[:i]})
+		}
+		j := strings.IndexByte(format[i:], '}')
+		if j < 0 {
+			return nil, fmt.Errorf("unclosed tag %q", format[i:])
+		}
+		name := format[i+2 : i+j]
+		format = format[i+j+1:]
+		p, err := compileTag(name, names)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// compileTag resolves a tag name into a part.
+func compileTag(name string, names map[string]int) (part, error) {
+	if color, ok := colors[name]; ok {
+		return part{text: color}, nil
+	}
+	if flag, ok := names[name]; ok {
+		return part{name: name, flag: flag}, nil
+	}
+	for _, kind := range params {
+		if strings.HasPrefix(name, kind) && len(name) > len(kind) {
+			return part{name: name, kind: kind, param: name[len(kind):]}, nil
+		}
+	}
+	return part{}, fmt.Errorf("unknown tag %q", "${"+name+"}")
+}
+
+// toString renders a tag value in text output.
+func toString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
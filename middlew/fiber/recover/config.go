@@ -0,0 +1,49 @@
+package recover
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// Repanic panics again once the panic is logged, i.e. in development
+	// mode, instead of converting it into a 500 response.
+	//
+	// Optional. Default: false
+	Repanic bool
+
+	// Context Key for Request ID
+	//
+	// Default: "requestid"
+	ContextKeyRID string
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:          nil,
+	Repanic:       false,
+	ContextKeyRID: requestid.ConfigDefault.ContextKey, // "requestid",
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.ContextKeyRID == "" {
+		cfg.ContextKeyRID = ConfigDefault.ContextKeyRID
+	}
+	return cfg
+}
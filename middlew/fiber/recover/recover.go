@@ -0,0 +1,51 @@
+// Package recover provides a fiber middleware recovering from panics in the
+// handlers, logging them through a log.Logger. It is a companion of the
+// logger middleware, which it should follow:
+//
+//	app.Use(logger.New(log.Current))
+//	app.Use(recover.New(log.Current))
+package recover
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roninzo/log"
+)
+
+// New creates a fiber handler recovering from panics. A panic is logged at
+// Error along with its stack trace, request id, route and method, and the
+// request is handed to the app error handler as a 500 error.
+func New(lgr log.Logger, config ...Config) fiber.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	// Return new handler
+	return func(c *fiber.Ctx) (err error) {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Catch panics
+		defer func() {
+			if r := recover(); r != nil {
+				lgr.Error(log.MesgFiberRecover, log.Map{
+					"panic":           fmt.Sprint(r),
+					"stack":           string(debug.Stack()),
+					cfg.ContextKeyRID: c.Locals(cfg.ContextKeyRID),
+					"route":           c.Route().Path,
+					"method":          c.Method(),
+				})
+				if cfg.Repanic {
+					panic(r)
+				}
+				err = fiber.ErrInternalServerError
+			}
+		}()
+
+		// Return err if exist, else move to next handler
+		return c.Next()
+	}
+}
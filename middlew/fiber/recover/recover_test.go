@@ -0,0 +1,70 @@
+package recover
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	stdlog "log"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handled := 0
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			handled++
+			return fiber.DefaultErrorHandler(c, err)
+		},
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "rid-1")
+		return c.Next()
+	})
+	app.Use(New(std.New(stdlog.New(buf, "", 0))))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/users/1", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, 1, handled)
+
+	out := buf.String()
+	assert.Contains(t, out, "[ERROR] "+log.MesgFiberRecover)
+	assert.Contains(t, out, "[panic=boom]")
+	assert.Contains(t, out, "[requestid=rid-1]")
+	assert.Contains(t, out, "[route=/users/:id]")
+	assert.Contains(t, out, "[method=GET]")
+	assert.Contains(t, out, "runtime/debug.Stack")
+}
+
+func TestRepanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = c.SendString(fmt.Sprint("repanic: ", r))
+			}
+		}()
+		return c.Next()
+	})
+	app.Use(New(std.New(stdlog.New(buf, "", 0)), Config{Repanic: true}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "repanic: boom", string(body))
+	assert.Contains(t, buf.String(), "[panic=boom]")
+}
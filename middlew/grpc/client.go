@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/roninzo/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor returns a client interceptor logging unary calls.
+func UnaryClientInterceptor(lgr log.Logger, config ...Config) grpc.UnaryClientInterceptor {
+	// Set default config
+	cfg := configDefault(config...)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx, rid := outgoingRID(ctx, cfg)
+		p := &peer.Peer{}
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...)
+		if cfg.Next == nil || !cfg.Next(method) {
+			logCall(peer.NewContext(ctx, p), lgr, cfg, log.MesgGRPCClient, method, rid, start, err)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor logging stream calls,
+// once they are over, i.e. when receiving from the stream fails or ends, when
+// the response of a client stream is received, or when the context of a call
+// abandoned before its end is done.
+func StreamClientInterceptor(lgr log.Logger, config ...Config) grpc.StreamClientInterceptor {
+	// Set default config
+	cfg := configDefault(config...)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx, rid := outgoingRID(ctx, cfg)
+		p := &peer.Peer{}
+		done := func(err error, p *peer.Peer) {
+			if cfg.Next == nil || !cfg.Next(method) {
+				ctx := ctx
+				if p != nil {
+					ctx = peer.NewContext(ctx, p)
+				}
+				logCall(ctx, lgr, cfg, log.MesgGRPCClient, method, rid, start, err)
+			}
+		}
+		cs, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(p))...)
+		if err != nil {
+			done(err, p)
+			return nil, err
+		}
+		s := &clientStream{ClientStream: cs, desc: desc, peer: p, done: done}
+		go s.watch(ctx)
+		return s, nil
+	}
+}
+
+// clientStream logs a client stream call once it is over.
+type clientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	peer *peer.Peer // set by grpc once the stream is finished
+	once sync.Once
+	done func(err error, p *peer.Peer)
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(nil, s.peer)
+	case err != nil:
+		s.finish(err, s.peer)
+	case !s.desc.ServerStreams: // the single response of a client stream
+		s.finish(nil, s.peer)
+	}
+	return err
+}
+
+// watch logs the call when ctx is done before the stream ends, i.e. when the
+// call is abandoned without receiving its end. The peer is left out, grpc
+// setting it concurrently.
+func (s *clientStream) watch(ctx context.Context) {
+	<-s.ClientStream.Context().Done() // done with ctx, or once the stream ends
+	if err := ctx.Err(); err != nil {
+		s.finish(status.FromContextError(err).Err(), nil)
+	}
+}
+
+// finish logs the call once.
+func (s *clientStream) finish(err error, p *peer.Peer) {
+	s.once.Do(func() { s.done(err, p) })
+}
+
+// outgoingRID returns the request id of the outgoing metadata, or else sets
+// the one of the context when any.
+func outgoingRID(ctx context.Context, cfg Config) (context.Context, string) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if vals := md.Get(cfg.MetadataKey); len(vals) > 0 && vals[0] != "" {
+			return ctx, vals[0]
+		}
+	}
+	rid := RequestID(ctx)
+	if rid != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, cfg.MetadataKey, rid)
+	}
+	return ctx, rid
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/roninzo/log/levels"
+	"google.golang.org/grpc/codes"
+)
+
+// Config defines the config for the interceptors.
+type Config struct {
+	// Next defines a function to skip logging a call when returned true,
+	// given its full method name, i.e. "/grpc.health.v1.Health/Check".
+	//
+	// Optional. Default: nil
+	Next func(method string) bool
+
+	// MetadataKey is the metadata key holding the request id. Server
+	// interceptors keep the request id of incoming calls, or else generate
+	// one; client interceptors set it from the context when missing.
+	//
+	// Optional. Default: "x-request-id"
+	MetadataKey string
+
+	// Generator generates request ids.
+	//
+	// Optional. Default: 32 random hexadecimal characters
+	Generator func() string
+
+	// LevelFunc returns the level of the log entry of a call, given its code.
+	//
+	// Optional. Default: DefaultLevel
+	LevelFunc func(code codes.Code) levels.Type
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:        nil,
+	MetadataKey: "x-request-id",
+	Generator:   generate,
+	LevelFunc:   DefaultLevel,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.MetadataKey == "" {
+		cfg.MetadataKey = ConfigDefault.MetadataKey
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = ConfigDefault.Generator
+	}
+	if cfg.LevelFunc == nil {
+		cfg.LevelFunc = ConfigDefault.LevelFunc
+	}
+	return cfg
+}
+
+// DefaultLevel maps the codes of the calls to levels: Info for the codes of
+// successful calls and client errors, Warn for the codes of calls which may
+// succeed later, and Error for the codes of server errors.
+func DefaultLevel(code codes.Code) levels.Type {
+	switch code {
+	case codes.OK,
+		codes.Canceled,
+		codes.InvalidArgument,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.Unauthenticated:
+		return levels.Info
+	case codes.PermissionDenied,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.FailedPrecondition,
+		codes.Aborted,
+		codes.OutOfRange:
+		return levels.Warn
+	default: // Unknown, Unimplemented, Internal, Unavailable, DataLoss
+		return levels.Error
+	}
+}
+
+// generate returns a random request id.
+func generate() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
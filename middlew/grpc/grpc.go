@@ -0,0 +1,108 @@
+// Package grpc provides gRPC server and client interceptors logging calls
+// through any log.Logger, for example:
+//
+//	import(
+//	    "google.golang.org/grpc"
+//	    "github.com/roninzo/log"
+//	    mw "github.com/roninzo/log/middlew/grpc"
+//	)
+//
+//	func main() {
+//	    srv := grpc.NewServer(
+//	        grpc.UnaryInterceptor(mw.UnaryServerInterceptor(log.Current)),
+//	        grpc.StreamInterceptor(mw.StreamServerInterceptor(log.Current)),
+//	    )
+//	    ...
+//	}
+//
+// Handlers get a logger carrying the request id and method of the call from
+// FromContext.
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Field keys of the log entries.
+const (
+	KeyMethod  = "method"
+	KeyCode    = "code"
+	KeyLatency = "latency"
+	KeyPeer    = "peer"
+	KeyRID     = "requestid"
+	KeyError   = "error"
+)
+
+// contextKey is the type of the keys of the context values.
+type contextKey int
+
+const (
+	ridKey contextKey = iota
+	loggerKey
+)
+
+// RequestID returns the request id of a call context, or "".
+func RequestID(ctx context.Context) string {
+	rid, _ := ctx.Value(ridKey).(string)
+	return rid
+}
+
+// FromContext returns a logger for handlers behind the server interceptors,
+// carrying the request id and method of the call. It returns log.Current
+// when the interceptors did not handle the call.
+func FromContext(ctx context.Context) log.Logger {
+	if lgr, ok := ctx.Value(loggerKey).(log.Logger); ok {
+		return lgr
+	}
+	return log.Current
+}
+
+// withLogger returns a context carrying a request id and a logger.
+func withLogger(ctx context.Context, lgr log.Logger, rid, method string) context.Context {
+	ctx = context.WithValue(ctx, ridKey, rid)
+	return context.WithValue(ctx, loggerKey, log.With(lgr, log.Map{
+		KeyRID:    rid,
+		KeyMethod: method,
+	}))
+}
+
+// logCall logs a call at the level of the code of its error.
+func logCall(ctx context.Context, lgr log.Logger, cfg Config, msg, method, rid string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := log.Map{
+		KeyMethod:  method,
+		KeyCode:    code.String(),
+		KeyLatency: time.Since(start).String(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[KeyPeer] = p.Addr.String()
+	}
+	if rid != "" {
+		fields[KeyRID] = rid
+	}
+	if err != nil {
+		fields[KeyError] = err.Error()
+	}
+	switch cfg.LevelFunc(code) {
+	case levels.Trace:
+		lgr.Trace(msg, fields)
+	case levels.Debug:
+		lgr.Debug(msg, fields)
+	case levels.Info:
+		lgr.Info(msg, fields)
+	case levels.Warn:
+		lgr.Warn(msg, fields)
+	case levels.Error:
+		lgr.Error(msg, fields)
+	case levels.Panic:
+		lgr.Panic(msg, fields)
+	case levels.Fatal:
+		lgr.Fatal(msg, fields)
+	}
+}
@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	stdlog "log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/impl/std"
+	"github.com/roninzo/log/levels"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// collectDesc is a client stream service, collecting the services of health
+// check requests until the client closes the stream.
+var collectDesc = grpc.ServiceDesc{
+	ServiceName: "test.Collector",
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Collect",
+		ClientStreams: true,
+		Handler: func(_ interface{}, stream grpc.ServerStream) error {
+			for {
+				err := stream.RecvMsg(&grpc_health_v1.HealthCheckRequest{})
+				if err == io.EOF {
+					return stream.SendMsg(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+				}
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+// dial starts an in-process server with the server interceptors, and dials
+// it with the client interceptors.
+func dial(t *testing.T, srvOut, cliOut *syncBuffer) (*grpc.ClientConn, func()) {
+	srvLgr := std.New(stdlog.New(srvOut, "", 0))
+	cliLgr := std.New(stdlog.New(cliOut, "", 0))
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			UnaryServerInterceptor(srvLgr),
+			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				FromContext(ctx).Info("in handler")
+				return handler(ctx, req)
+			},
+		),
+		grpc.StreamInterceptor(StreamServerInterceptor(srvLgr)),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("up", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	srv.RegisterService(&collectDesc, nil)
+	go func() { _ = srv.Serve(lis) }()
+
+	cc, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(cliLgr)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(cliLgr)),
+	)
+	assert.NoError(t, err)
+	return cc, func() {
+		cc.Close()
+		srv.Stop()
+	}
+}
+
+func TestUnary(t *testing.T) {
+	srvOut, cliOut := &syncBuffer{}, &syncBuffer{}
+	cc, stop := dial(t, srvOut, cliOut)
+	defer stop()
+	client := grpc_health_v1.NewHealthClient(cc)
+
+	// Test the request id is propagated from the context
+	ctx := context.WithValue(context.Background(), ridKey, "rid-1")
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "up"})
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(srvOut.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "in handler")
+	assert.Contains(t, lines[0], "[requestid=rid-1]")
+	assert.Contains(t, lines[0], "[method=/grpc.health.v1.Health/Check]")
+	for _, field := range []string{
+		"[INFO]  " + log.MesgGRPCServer,
+		"[code=OK]",
+		"[method=/grpc.health.v1.Health/Check]",
+		"[requestid=rid-1]",
+		"[peer=bufconn]",
+		"[latency=",
+	} {
+		assert.Contains(t, lines[1], field)
+	}
+	for _, field := range []string{
+		"[INFO]  " + log.MesgGRPCClient,
+		"[code=OK]",
+		"[requestid=rid-1]",
+		"[peer=bufconn]",
+	} {
+		assert.Contains(t, cliOut.String(), field)
+	}
+
+	// Test a request id is generated when missing, and kept from metadata
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "down"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, `\[requestid=[0-9a-f]{32}\]`, srvOut.String())
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "rid-2")
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "up"})
+	assert.NoError(t, err)
+	assert.Contains(t, srvOut.String(), "[requestid=rid-2]")
+	assert.Contains(t, cliOut.String(), "[requestid=rid-2]")
+
+	// Test the level is mapped from the code
+	err = cc.Invoke(context.Background(), "/foo.Bar/Baz", &grpc_health_v1.HealthCheckRequest{}, &grpc_health_v1.HealthCheckResponse{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	assert.Contains(t, cliOut.String(), "[ERROR] "+log.MesgGRPCClient)
+	assert.Contains(t, cliOut.String(), "[code=Unimplemented]")
+}
+
+func TestStream(t *testing.T) {
+	srvOut, cliOut := &syncBuffer{}, &syncBuffer{}
+	cc, stop := dial(t, srvOut, cliOut)
+	defer stop()
+	client := grpc_health_v1.NewHealthClient(cc)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ridKey, "rid-1"))
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "up"})
+	assert.NoError(t, err)
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	assert.Empty(t, cliOut.String(), "logged once over")
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Contains(t, cliOut.String(), "[code=Canceled]")
+	assert.Contains(t, cliOut.String(), "[method=/grpc.health.v1.Health/Watch]")
+	assert.Contains(t, cliOut.String(), "[requestid=rid-1]")
+	assert.Eventually(t, func() bool {
+		return strings.Contains(srvOut.String(), "[method=/grpc.health.v1.Health/Watch]")
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, srvOut.String(), "[requestid=rid-1]")
+}
+
+func TestClientStream(t *testing.T) {
+	srvOut, cliOut := &syncBuffer{}, &syncBuffer{}
+	cc, stop := dial(t, srvOut, cliOut)
+	defer stop()
+
+	// Test a client stream is logged once its response is received
+	desc := &collectDesc.Streams[0]
+	stream, err := cc.NewStream(context.Background(), desc, "/test.Collector/Collect")
+	assert.NoError(t, err)
+	assert.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{Service: "a"}))
+	assert.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{Service: "b"}))
+	assert.NoError(t, stream.CloseSend())
+	assert.Empty(t, cliOut.String(), "logged once over")
+	resp := &grpc_health_v1.HealthCheckResponse{}
+	assert.NoError(t, stream.RecvMsg(resp))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	assert.Contains(t, cliOut.String(), "[code=OK]")
+	assert.Contains(t, cliOut.String(), "[method=/test.Collector/Collect]")
+
+	// Test a server stream abandoned before its end is logged
+	cliOut.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	watch, err := grpc_health_v1.NewHealthClient(cc).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "up"})
+	assert.NoError(t, err)
+	_, err = watch.Recv()
+	assert.NoError(t, err)
+	cancel()
+	assert.Eventually(t, func() bool {
+		return strings.Contains(cliOut.String(), "[code=Canceled]")
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, strings.Count(cliOut.String(), log.MesgGRPCClient), "logged once")
+}
+
+func TestDefaultLevel(t *testing.T) {
+	assert.Equal(t, levels.Info, DefaultLevel(codes.OK))
+	assert.Equal(t, levels.Info, DefaultLevel(codes.NotFound))
+	assert.Equal(t, levels.Warn, DefaultLevel(codes.DeadlineExceeded))
+	assert.Equal(t, levels.Error, DefaultLevel(codes.Internal))
+	assert.Equal(t, levels.Error, DefaultLevel(codes.Unknown))
+}
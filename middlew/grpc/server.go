@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/roninzo/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor returns a server interceptor logging unary calls.
+func UnaryServerInterceptor(lgr log.Logger, config ...Config) grpc.UnaryServerInterceptor {
+	// Set default config
+	cfg := configDefault(config...)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		rid := incomingRID(ctx, cfg)
+		resp, err := handler(withLogger(ctx, lgr, rid, info.FullMethod), req)
+		if cfg.Next == nil || !cfg.Next(info.FullMethod) {
+			logCall(ctx, lgr, cfg, log.MesgGRPCServer, info.FullMethod, rid, start, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor logging stream calls,
+// once they are over.
+func StreamServerInterceptor(lgr log.Logger, config ...Config) grpc.StreamServerInterceptor {
+	// Set default config
+	cfg := configDefault(config...)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := ss.Context()
+		rid := incomingRID(ctx, cfg)
+		err := handler(srv, &serverStream{ss, withLogger(ctx, lgr, rid, info.FullMethod)})
+		if cfg.Next == nil || !cfg.Next(info.FullMethod) {
+			logCall(ctx, lgr, cfg, log.MesgGRPCServer, info.FullMethod, rid, start, err)
+		}
+		return err
+	}
+}
+
+// serverStream overrides the context of a server stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// incomingRID returns the request id of the incoming metadata, or a new one.
+func incomingRID(ctx context.Context, cfg Config) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(cfg.MetadataKey); len(vals) > 0 && vals[0] != "" {
+			return vals[0]
+		}
+	}
+	return cfg.Generator()
+}
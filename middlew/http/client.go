@@ -0,0 +1,277 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// Redacted replaces the values of the query parameters which are not allowed.
+const Redacted = log.Redacted
+
+// SensitiveHeaders are the headers which values are replaced with Redacted in
+// the request and response dumps.
+var SensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// AllQueryParams is the QueryParams allow-list logging the values of all query
+// parameters as is.
+var AllQueryParams = []string{"*"}
+
+// ClientConfig defines the config for the client logger.
+type ClientConfig struct {
+	// Next defines a function to skip logging a request when returned true.
+	//
+	// Optional. Default: nil
+	Next func(r *http.Request) bool
+
+	// Header is the request header holding the request id, set from the
+	// request context when missing, see RequestID.
+	//
+	// Optional. Default: "X-Request-ID"
+	Header string
+
+	// QueryParams is the allow-list of the query parameters which are logged
+	// as is; the values of other parameters are replaced with Redacted. The
+	// AllQueryParams list disables redaction.
+	//
+	// Optional. Default: nil, all values are redacted
+	QueryParams []string
+
+	// Tags overrides the field keys of some flags, i.e.
+	// map[int]string{Lstatus: "code"}.
+	//
+	// Optional. Default: nil
+	Tags map[int]string
+
+	// LevelFunc returns the level of the log entry of a request, given its
+	// response or error.
+	//
+	// Optional. Default: nil, for Error on errors or 5xx responses, Warn on
+	// 4xx responses, and Info otherwise
+	LevelFunc func(r *http.Request, resp *http.Response, err error) levels.Type
+
+	// Dump logs the request and response dumps at Trace, with their bodies
+	// when DumpBody is set. The URL is redacted as with QueryParams, and the
+	// values of the SensitiveHeaders are replaced with Redacted.
+	//
+	// Optional. Default: false
+	Dump     bool
+	DumpBody bool
+}
+
+// ClientConfigDefault is the default client config
+var ClientConfigDefault = ClientConfig{
+	Next:   nil,
+	Header: "X-Request-ID",
+}
+
+// Helper function to set default values
+func clientConfigDefault(config ...ClientConfig) ClientConfig {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ClientConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Header == "" {
+		cfg.Header = ClientConfigDefault.Header
+	}
+	return cfg
+}
+
+// Transport is an http.RoundTripper logging outgoing requests.
+type Transport struct {
+	log.Logger
+	ClientConfig
+
+	// Base is the wrapped round tripper.
+	Base http.RoundTripper
+}
+
+// NewTransport wraps a round tripper, http.DefaultTransport when nil, so that
+// it logs the requests it sends, i.e.
+//
+//	client := &http.Client{Transport: mw.NewTransport(log.Current, nil)}
+func NewTransport(lgr log.Logger, base http.RoundTripper, config ...ClientConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		Logger:       lgr,
+		ClientConfig: clientConfigDefault(config...),
+		Base:         base,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Propagate request id
+	rid := req.Header.Get(t.Header)
+	if rid == "" {
+		if rid = RequestID(req.Context()); rid != "" {
+			req = req.Clone(req.Context()) // round trippers must not modify requests
+			req.Header.Set(t.Header, rid)
+		}
+	}
+	if t.Next != nil && t.Next(req) {
+		return t.Base.RoundTrip(req)
+	}
+
+	dump := t.Dump && t.Level() <= levels.Trace
+	if dump {
+		var err error
+		if req, err = t.dumpRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	latency := time.Since(start)
+
+	fields := log.Map{
+		t.key(Lmethod):    req.Method,
+		t.key(LURL):       t.redact(req.URL),
+		t.key(Llatency):   latency.String(),
+		t.key(LbytesSent): req.ContentLength,
+	}
+	if rid != "" {
+		fields[t.key(Lrid)] = rid
+	}
+	if resp != nil {
+		fields[t.key(Lstatus)] = resp.StatusCode
+		fields[t.key(LbytesReceived)] = resp.ContentLength
+	}
+	if err != nil {
+		fields[t.key(Lerror)] = err.Error()
+	}
+	logAt(t.Logger, t.level(req, resp, err), log.MesgHTTPClient, fields)
+
+	if dump && resp != nil {
+		t.dumpResponse(resp)
+	}
+	return resp, err
+}
+
+// key returns the field key of a flag.
+func (t *Transport) key(flag int) string {
+	if key, ok := t.Tags[flag]; ok {
+		return key
+	}
+	return Tags[flag]
+}
+
+// dumpRequest logs the dump of a clone of a request, and returns the request
+// to send. A body which cannot be read again through GetBody is buffered, and
+// sent by a clone of the request, as round trippers must not modify requests.
+func (t *Transport) dumpRequest(req *http.Request) (*http.Request, error) {
+	c := req.Clone(req.Context())
+	c.URL = t.redactURL(req.URL)
+	mask(c.Header)
+	body := t.DumpBody && req.Body != nil && req.Body != http.NoBody
+	switch {
+	case !body:
+	case req.GetBody != nil:
+		var err error
+		if c.Body, err = req.GetBody(); err != nil {
+			return req, nil // not dumped, but can be sent
+		}
+	default:
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		c.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+	if b, err := httputil.DumpRequestOut(c, body); err == nil {
+		t.Trace(log.MesgHTTPDumpRequest, log.Map{"dump": string(b)})
+	}
+	return req, nil
+}
+
+// dumpResponse logs the dump of a response, with its sensitive headers masked.
+// Its body, when dumped, is replaced with an equivalent one.
+func (t *Transport) dumpResponse(resp *http.Response) {
+	c := *resp
+	c.Header = resp.Header.Clone()
+	mask(c.Header)
+	b, err := httputil.DumpResponse(&c, t.DumpBody)
+	resp.Body = c.Body
+	if err == nil {
+		t.Trace(log.MesgHTTPDumpResponse, log.Map{"dump": string(b)})
+	}
+}
+
+// mask replaces the values of the SensitiveHeaders with Redacted.
+func mask(header http.Header) {
+	for _, key := range SensitiveHeaders {
+		if _, ok := header[http.CanonicalHeaderKey(key)]; ok {
+			header.Set(key, Redacted)
+		}
+	}
+}
+
+// level returns the level of the log entry of a request.
+func (t *Transport) level(req *http.Request, resp *http.Response, err error) levels.Type {
+	if t.LevelFunc != nil {
+		return t.LevelFunc(req, resp, err)
+	}
+	switch {
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		return levels.Error
+	case resp.StatusCode >= http.StatusBadRequest:
+		return levels.Warn
+	default:
+		return levels.Info
+	}
+}
+
+// redact returns a URL without user info, and with the values of the query
+// parameters which are not in QueryParams replaced with Redacted.
+func (t *Transport) redact(u *url.URL) string {
+	return t.redactURL(u).String()
+}
+
+// redactURL returns a copy of a URL, redacted as with redact.
+func (t *Transport) redactURL(u *url.URL) *url.URL {
+	r := *u
+	r.User = nil
+	allowed := make(map[string]bool, len(t.QueryParams))
+	for _, param := range t.QueryParams {
+		allowed[param] = true
+	}
+	if allowed["*"] || r.RawQuery == "" {
+		return &r
+	}
+	values := r.Query()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var query []string
+	for _, key := range keys {
+		for _, val := range values[key] {
+			if !allowed[key] {
+				val = Redacted
+			}
+			query = append(query, url.QueryEscape(key)+"="+url.QueryEscape(val))
+		}
+	}
+	r.RawQuery = strings.Join(query, "&")
+	return &r
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/roninzo/log/levels"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(r *http.Request) bool
+
+	// Fields defines what to log: the fields of the flags which are set, as
+	// with the flags of the standard log package.
+	//
+	// Optional. Default: LstdFlags (Lpid | LIP | Lrid | Lstatus | LbytesSent | Llatency | Lmethod | Lpath)
+	Fields int
+
+	// Include adds fields to the ones of Fields, i.e. LUA | Lreferer.
+	//
+	// Optional. Default: 0
+	Include int
+
+	// Exclude removes fields from the ones of Fields and Include, i.e. Lpid.
+	//
+	// Optional. Default: 0
+	Exclude int
+
+	// Tags overrides the field keys of some flags, i.e.
+	// map[int]string{Lstatus: "code"}.
+	//
+	// Optional. Default: nil
+	Tags map[int]string
+
+	// TimeFormat https://programming.guide/go/format-parse-string-time-date-example.html
+	//
+	// Optional. Default: 15:04:05
+	TimeFormat string
+
+	// Header is the request and response header holding the request id. The
+	// request id of incoming requests is kept, or else generated.
+	//
+	// Optional. Default: "X-Request-ID"
+	Header string
+
+	// Generator generates request ids.
+	//
+	// Optional. Default: 32 random hexadecimal characters
+	Generator func() string
+
+	// LevelFunc returns the level of the access log entry of a request, given
+	// its response status.
+	//
+	// Optional. Default: nil, for Error on 5xx responses, Warn on 4xx
+	// responses or slow requests, and Info otherwise
+	LevelFunc func(r *http.Request, status int) levels.Type
+
+	// SlowThreshold is the latency above which a request is logged at Warn,
+	// with a "slow" field. A zero value disables it.
+	//
+	// Optional. Default: 0
+	SlowThreshold time.Duration
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:       nil,
+	Fields:     LstdFlags,
+	TimeFormat: "15:04:05",
+	Header:     "X-Request-ID",
+	Generator:  generate,
+}
+
+// Helper function to set default values
+func configDefault(config ...Config) Config {
+	// Return default config if nothing provided
+	if len(config) < 1 {
+		return ConfigDefault
+	}
+
+	// Override default config
+	cfg := config[0]
+
+	// Set default values
+	if cfg.Fields == 0 {
+		cfg.Fields = ConfigDefault.Fields
+	}
+	if cfg.TimeFormat == "" {
+		cfg.TimeFormat = ConfigDefault.TimeFormat
+	}
+	if cfg.Header == "" {
+		cfg.Header = ConfigDefault.Header
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = ConfigDefault.Generator
+	}
+	return cfg
+}
+
+// generate returns a random request id.
+func generate() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
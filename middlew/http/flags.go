@@ -0,0 +1,45 @@
+package http
+
+// Logger flags, as with the flags of the standard log package.
+const (
+	Lpid = 1 << iota
+	Ltime
+	Lreferer
+	Lprotocol
+	LIP
+	LIPs
+	Lhost
+	Lmethod
+	Lpath
+	LURL
+	LUA
+	Llatency
+	Lstatus
+	LbytesSent
+	LbytesReceived
+	Lrid
+	Lerror
+	LstdFlags = Lpid | LIP | Lrid | Lstatus | LbytesSent | Llatency | Lmethod | Lpath
+	LallFlags = Lerror<<1 - 1
+)
+
+// Tags are the field keys of the flags.
+var Tags = map[int]string{
+	Lpid:           "pid",
+	Ltime:          "time",
+	Lreferer:       "referer",
+	Lprotocol:      "protocol",
+	LIP:            "ip",
+	LIPs:           "ips",
+	Lhost:          "host",
+	Lmethod:        "method",
+	Lpath:          "path",
+	LURL:           "url",
+	LUA:            "ua",
+	Llatency:       "latency",
+	Lstatus:        "status",
+	LbytesSent:     "bytes_sent",
+	LbytesReceived: "bytes_received",
+	Lrid:           "requestid",
+	Lerror:         "error",
+}
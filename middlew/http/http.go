@@ -0,0 +1,227 @@
+// Package http provides a net/http access logger middleware, the equivalent
+// of middlew/fiber/logger for plain net/http handlers and routers such as chi,
+// logging through any log.Logger:
+//
+//	import(
+//	    "net/http"
+//	    "github.com/roninzo/log"
+//	    mw "github.com/roninzo/log/middlew/http"
+//	)
+//
+//	func main() {
+//	    mux := http.NewServeMux()
+//	    mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+//	        mw.FromContext(r.Context()).Info("in handler") // => [requestid=...]
+//	    })
+//	    http.ListenAndServe(":8080", mw.New(log.Current)(mux))
+//	}
+//
+// NewTransport provides the equivalent for outgoing requests, propagating the
+// request id of the incoming ones.
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/roninzo/log"
+	"github.com/roninzo/log/levels"
+)
+
+// contextKey is the type of the keys of the request context values.
+type contextKey int
+
+const (
+	ridKey contextKey = iota
+	loggerKey
+)
+
+// RequestID returns the request id of a request context, or "".
+func RequestID(ctx context.Context) string {
+	rid, _ := ctx.Value(ridKey).(string)
+	return rid
+}
+
+// FromContext returns a logger for handlers behind the middleware, carrying
+// the request id, method and path of the request. It returns log.Current
+// when the middleware did not handle the request.
+func FromContext(ctx context.Context) log.Logger {
+	if lgr, ok := ctx.Value(loggerKey).(log.Logger); ok {
+		return lgr
+	}
+	return log.Current
+}
+
+// Logger is a net/http access logger.
+type Logger struct {
+	log.Logger
+	Config
+
+	pid  string
+	flag int            // flags of the fields to log
+	keys map[int]string // field keys of the flags
+}
+
+// New creates a net/http access logger middleware.
+func New(lgr log.Logger, config ...Config) func(http.Handler) http.Handler {
+	// Set default config
+	cfg := configDefault(config...)
+
+	// Current logger.
+	l := &Logger{
+		Logger: lgr,
+		Config: cfg,
+		pid:    strconv.Itoa(os.Getpid()),
+		flag:   (cfg.Fields | cfg.Include) &^ cfg.Exclude,
+		keys:   make(map[int]string, len(Tags)),
+	}
+	for flag, key := range Tags {
+		l.keys[flag] = key
+	}
+	for flag, key := range l.Tags {
+		l.keys[flag] = key
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Don't execute middleware if Next returns true
+			if l.Next != nil && l.Next(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+
+			// Propagate request id, through the context not to modify the
+			// request headers
+			rid := r.Header.Get(l.Header)
+			if rid == "" {
+				rid = l.Generator()
+			}
+			w.Header().Set(l.Header, rid)
+			ctx := context.WithValue(r.Context(), ridKey, rid)
+			ctx = context.WithValue(ctx, loggerKey, log.With(l.Logger, log.Map{
+				l.keys[Lrid]:    rid,
+				l.keys[Lmethod]: r.Method,
+				l.keys[Lpath]:   r.URL.Path,
+			}))
+			r = r.WithContext(ctx)
+
+			// Handle request
+			rb := &body{ReadCloser: r.Body}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = rb
+			}
+			ww, rw := wrap(w)
+			defer func() { // log panicking requests too
+				p := recover()
+				if p != nil && rw.status == 0 {
+					rw.status = http.StatusInternalServerError
+				}
+				latency := time.Since(start)
+
+				fields := l.fields(r, rw, rb, latency)
+				slow := l.SlowThreshold > 0 && latency > l.SlowThreshold
+				if slow {
+					fields["slow"] = true
+				}
+				if p != nil && l.flag&Lerror != 0 {
+					fields[l.keys[Lerror]] = fmt.Sprint(p)
+				}
+				logAt(l.Logger, l.level(r, rw.Status(), slow), log.MesgHTTPLogger, fields)
+				if p != nil {
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// level returns the level of the access log entry of a request.
+func (l *Logger) level(r *http.Request, status int, slow bool) levels.Type {
+	if l.LevelFunc != nil {
+		return l.LevelFunc(r, status)
+	}
+	switch {
+	case status >= http.StatusInternalServerError:
+		return levels.Error
+	case status >= http.StatusBadRequest || slow:
+		return levels.Warn
+	default:
+		return levels.Info
+	}
+}
+
+// logAt logs at the given level.
+func logAt(lgr log.Logger, level levels.Type, msg ...interface{}) {
+	switch level {
+	case levels.Trace:
+		lgr.Trace(msg...)
+	case levels.Debug:
+		lgr.Debug(msg...)
+	case levels.Info:
+		lgr.Info(msg...)
+	case levels.Warn:
+		lgr.Warn(msg...)
+	case levels.Error:
+		lgr.Error(msg...)
+	case levels.Panic:
+		lgr.Panic(msg...)
+	case levels.Fatal:
+		lgr.Fatal(msg...)
+	}
+}
+
+// fields returns the fields of the access log entry.
+func (l *Logger) fields(r *http.Request, rw *responseWriter, rb *body, latency time.Duration) log.Map {
+	fields := log.Map{}
+	for flag, key := range l.keys {
+		if l.flag&flag == 0 {
+			continue
+		}
+		switch flag {
+		case Lpid:
+			fields[key] = l.pid
+		case Ltime:
+			fields[key] = time.Now().Format(l.TimeFormat)
+		case Lreferer:
+			fields[key] = r.Referer()
+		case Lprotocol:
+			fields[key] = r.Proto
+		case LIP:
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			fields[key] = host
+		case LIPs:
+			fields[key] = r.Header.Get("X-Forwarded-For")
+		case Lhost:
+			fields[key] = r.Host
+		case Lmethod:
+			fields[key] = r.Method
+		case Lpath:
+			fields[key] = r.URL.Path
+		case LURL:
+			fields[key] = r.URL.RequestURI()
+		case LUA:
+			fields[key] = r.UserAgent()
+		case Llatency:
+			fields[key] = latency.String()
+		case Lstatus:
+			fields[key] = rw.Status()
+		case LbytesSent:
+			fields[key] = rw.bytes
+		case LbytesReceived:
+			fields[key] = rb.bytes
+		case Lrid:
+			fields[key] = RequestID(r.Context())
+		}
+	}
+	return fields
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the status of the response, http.StatusOK when none was
+// written.
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// The wrappers below only implement http.Flusher and http.Hijacker when the
+// wrapped writer does, so that handlers relying on type assertions keep on
+// working.
+
+type flushWriter struct{ *responseWriter }
+
+func (w flushWriter) Flush() { w.flush() }
+
+type hijackWriter struct{ *responseWriter }
+
+func (w hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return w.hijack() }
+
+type flushHijackWriter struct{ *responseWriter }
+
+func (w flushHijackWriter) Flush()                                       { w.flush() }
+func (w flushHijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return w.hijack() }
+
+// wrap wraps a writer, preserving its http.Flusher and http.Hijacker
+// implementations.
+func wrap(w http.ResponseWriter) (http.ResponseWriter, *responseWriter) {
+	rw := &responseWriter{ResponseWriter: w}
+	_, flusher := w.(http.Flusher)
+	_, hijacker := w.(http.Hijacker)
+	switch {
+	case flusher && hijacker:
+		return flushHijackWriter{rw}, rw
+	case flusher:
+		return flushWriter{rw}, rw
+	case hijacker:
+		return hijackWriter{rw}, rw
+	}
+	return rw, rw
+}
+
+// body records the size of a request body.
+type body struct {
+	io.ReadCloser
+	bytes int64
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"github.com/roninzo/log/levels"
+)
+
+// With returns a Logger that adds fields to every message logged through lgr,
+// i.e. to carry request scoped values such as a request id. Fields passed
+// along with a message take precedence over these fields. The returned Logger
+// shares its prefix and level with lgr.
+func With(lgr Logger, fields Map) Logger {
+	if w, ok := lgr.(*withLogger); ok { // avoid nesting wrappers
+		return &withLogger{w.Logger, Merge(w.fields, fields)}
+	}
+	return &withLogger{lgr, fields}
+}
+
+// Fields returns the fields carried by a Logger returned by With, or nil.
+func Fields(lgr Logger) Map {
+	if w, ok := lgr.(*withLogger); ok {
+		return w.fields
+	}
+	return nil
+}
+
+// Merge returns a new Map holding the fields of all the maps. Later maps take
+// precedence over earlier ones.
+func Merge(maps ...Map) Map {
+	n := 0
+	for _, m := range maps {
+		n += len(m)
+	}
+	ret := make(Map, n)
+	for _, m := range maps {
+		for key, val := range m {
+			ret[key] = val
+		}
+	}
+	return ret
+}
+
+// withLogger is a Logger wrapper carrying fields.
+type withLogger struct {
+	Logger
+	fields Map
+}
+
+func (l *withLogger) Prefix(prefix ...string) string         { return l.Logger.Prefix(prefix...) }
+func (l *withLogger) Level(level ...levels.Type) levels.Type { return l.Logger.Level(level...) }
+
+func (l *withLogger) Trace(msg ...interface{}) { l.Logger.Trace(l.with(msg...)...) }
+func (l *withLogger) Debug(msg ...interface{}) { l.Logger.Debug(l.with(msg...)...) }
+func (l *withLogger) Info(msg ...interface{})  { l.Logger.Info(l.with(msg...)...) }
+func (l *withLogger) Warn(msg ...interface{})  { l.Logger.Warn(l.with(msg...)...) }
+func (l *withLogger) Error(msg ...interface{}) { l.Logger.Error(l.with(msg...)...) }
+func (l *withLogger) Panic(msg ...interface{}) { l.Logger.Panic(l.with(msg...)...) }
+func (l *withLogger) Fatal(msg ...interface{}) { l.Logger.Fatal(l.with(msg...)...) }
+
+func (l *withLogger) Tracef(template string, args ...interface{}) {
+	l.Logger.Tracef(template, l.with(args...)...)
+}
+func (l *withLogger) Debugf(template string, args ...interface{}) {
+	l.Logger.Debugf(template, l.with(args...)...)
+}
+func (l *withLogger) Infof(template string, args ...interface{}) {
+	l.Logger.Infof(template, l.with(args...)...)
+}
+func (l *withLogger) Warnf(template string, args ...interface{}) {
+	l.Logger.Warnf(template, l.with(args...)...)
+}
+func (l *withLogger) Errorf(template string, args ...interface{}) {
+	l.Logger.Errorf(template, l.with(args...)...)
+}
+func (l *withLogger) Panicf(template string, args ...interface{}) {
+	l.Logger.Panicf(template, l.with(args...)...)
+}
+func (l *withLogger) Fatalf(template string, args ...interface{}) {
+	l.Logger.Fatalf(template, l.with(args...)...)
+}
+
+// with appends the carried fields, merged with the message fields if any.
+func (l *withLogger) with(args ...interface{}) []interface{} {
+	if len(l.fields) == 0 {
+		return args
+	}
+	args, fields := ParseArgs(args...)
+	ret := make([]interface{}, 0, len(args)+1)
+	ret = append(ret, args...)
+	return append(ret, Merge(l.fields, fields))
+}